@@ -1,5 +1,8 @@
 -- Tüm maç kayıtlarını sil
 DELETE FROM matches;
+DELETE FROM simulated_weeks;
+DELETE FROM standings_history;
+DELETE FROM team_ratings;
 
 -- Tüm takım istatistiklerini sıfırla (id, name, strength hariç)
 UPDATE teams
@@ -1,6 +1,12 @@
 -- Eğer varsa eski tabloları sil
 DROP TABLE IF EXISTS matches;
 DROP TABLE IF EXISTS teams;
+DROP TABLE IF EXISTS simulated_weeks;
+DROP TABLE IF EXISTS standings_history;
+DROP TABLE IF EXISTS team_ratings;
+DROP TABLE IF EXISTS team_fair_play;
+DROP TABLE IF EXISTS seasons;
+DROP TABLE IF EXISTS schema_migrations;
 
 -- Takımlar tablosu
 CREATE TABLE teams (
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"errors"
+	"insider-case/services"
+	"net/http"
+	"strconv"
+)
+
+// errInvalidSeason "season" parametresi sayı değilse döner
+var errInvalidSeason = errors.New("'season' must be a positive integer")
+
+// seasonFromRequest isteğin "season" parametresindeki sezonu, yoksa güncel aktif sezonu döner
+func seasonFromRequest(seasons *services.SeasonService, r *http.Request) (int, error) {
+	seasonStr := r.URL.Query().Get("season")
+	if seasonStr == "" {
+		season, err := seasons.Current()
+		if err != nil {
+			return 0, err
+		}
+		return season.ID, nil
+	}
+
+	id, err := strconv.Atoi(seasonStr)
+	if err != nil || id < 1 {
+		return 0, errInvalidSeason
+	}
+	if _, err := seasons.Get(id); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// writeError bilinen servis hatalarını uygun HTTP durum koduna çevirir,
+// diğerlerini verilen mesajla 500 olarak döner
+func writeError(w http.ResponseWriter, message string, err error) {
+	switch {
+	case errors.Is(err, errInvalidSeason), errors.Is(err, services.ErrInvalidTeam),
+		errors.Is(err, services.ErrInvalidTiebreaker), errors.Is(err, services.ErrInvalidView),
+		errors.Is(err, services.ErrInvalidEngine), errors.Is(err, services.ErrInvalidParams),
+		errors.Is(err, services.ErrInvalidCalibration), errors.Is(err, services.ErrInvalidMatch):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, services.ErrSeasonNotFound), errors.Is(err, services.ErrNoActiveSeason),
+		errors.Is(err, services.ErrWeekNotScheduled), errors.Is(err, services.ErrTeamNotFound),
+		errors.Is(err, services.ErrMatchNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, services.ErrSeasonArchived), errors.Is(err, services.ErrScheduleLocked),
+		errors.Is(err, services.ErrWeekAlreadyPlayed), errors.Is(err, services.ErrWeekOutOfOrder),
+		errors.Is(err, services.ErrWeekNotPlayed), errors.Is(err, services.ErrSeasonFinished), errors.Is(err, services.ErrTeamNameTaken),
+		errors.Is(err, services.ErrTeamHasMatches), errors.Is(err, services.ErrTeamAlreadyScheduled):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, message+": "+err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// parseSeed isteğe bağlı "seed" query parametresini okur, yoksa yeni bir tohum üretir
+func parseSeed(r *http.Request) (int64, error) {
+	seedStr := r.URL.Query().Get("seed")
+	if seedStr == "" {
+		return services.NewSeed(), nil
+	}
+	return strconv.ParseInt(seedStr, 10, 64)
+}
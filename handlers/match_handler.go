@@ -1,4 +1,4 @@
-package router
+package handlers
 
 import (
 	"database/sql"
@@ -6,70 +6,233 @@ import (
 	"net/http"
 	"strconv"
 
-	"insider-case/models"
 	"insider-case/services"
+
+	"github.com/gorilla/mux"
 )
 
 type MatchHandler struct {
 	simulator *services.SimulatorService
+	seasons   *services.SeasonService
+	matches   *services.MatchService
 }
 
 func NewMatchHandler(db *sql.DB) *MatchHandler {
 	return &MatchHandler{
 		simulator: services.NewSimulatorService(db),
+		seasons:   services.NewSeasonService(db),
+		matches:   services.NewMatchService(db),
 	}
 }
 
-// POST /matches/simulate?week=1
-// Belirtilen haftanın maçlarını simüle edip sonucu kaydeder ve sonucu döner
-func (h *MatchHandler) SimulateMatchesHandler(w http.ResponseWriter, r *http.Request) {
-	weekStr := r.URL.Query().Get("week")
-	if weekStr == "" {
-		http.Error(w, "Missing 'week' query parameter", http.StatusBadRequest)
+// GET /matches?week=1&season=1
+// İsteğe bağlı olarak haftaya göre maçları listeler, yoksa sezonun tüm maçlarını döner.
+// Oynanmamış fikstür maçları da played=false ile listelenir
+func (h *MatchHandler) ListMatchesHandler(w http.ResponseWriter, r *http.Request) {
+	week := 0
+	if weekStr := r.URL.Query().Get("week"); weekStr != "" {
+		n, err := strconv.Atoi(weekStr)
+		if err != nil || n < 1 {
+			http.Error(w, "'week' must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		week = n
+	}
+
+	seasonID, err := seasonFromRequest(h.seasons, r)
+	if err != nil {
+		writeError(w, "Failed to fetch matches", err)
 		return
 	}
-	week, err := strconv.Atoi(weekStr)
-	if err != nil || week < 1 {
-		http.Error(w, "'week' must be a positive integer", http.StatusBadRequest)
+
+	matches, err := h.matches.ListMatches(seasonID, week)
+	if err != nil {
+		http.Error(w, "Failed to fetch matches: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	err = h.simulator.SimulateWeek(week)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(matches)
+}
+
+// GET /matches/{id} tek bir maçı takım isimleriyle döner
+func (h *MatchHandler) GetMatchHandler(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+	match, err := h.matches.GetMatch(id)
 	if err != nil {
-		http.Error(w, "Failed to simulate matches: "+err.Error(), http.StatusInternalServerError)
+		writeError(w, "Failed to get match", err)
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte("Matches simulated for week " + weekStr))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(match)
 }
 
-// GET /matches?week=1
-// İsteğe bağlı olarak haftaya göre maçları listeler, yoksa tüm maçları döner
-func (h *MatchHandler) ListMatchesHandler(w http.ResponseWriter, r *http.Request) {
-	weekStr := r.URL.Query().Get("week")
+// matchResultRequest PUT /matches/{id}/result gövdesi
+type matchResultRequest struct {
+	HomeGoals *int `json:"home_goals"`
+	AwayGoals *int `json:"away_goals"`
+}
 
-	var matches []models.Match
-	var err error
+// PUT /matches/{id}/result maçın skorunu elle girer (gerçek sonuç ya da düzeltme).
+// Puan tablosu, sıralama geçmişi ve Elo puanları yeniden hesaplanır; güncel maçı döner
+func (h *MatchHandler) SetResultHandler(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
 
-	if weekStr == "" {
-		matches, err = h.simulator.GetAllMatches()
-	} else {
-		week, err2 := strconv.Atoi(weekStr)
-		if err2 != nil || week < 1 {
+	var req matchResultRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.HomeGoals == nil || req.AwayGoals == nil {
+		http.Error(w, "'home_goals' and 'away_goals' are required", http.StatusBadRequest)
+		return
+	}
+
+	match, err := h.simulator.SetResult(id, *req.HomeGoals, *req.AwayGoals)
+	if err != nil {
+		writeError(w, "Failed to set match result", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(match)
+}
+
+// createMatchRequest POST /matches gövdesi; skor verilmezse maç oynanmamış fikstür olarak eklenir
+type createMatchRequest struct {
+	Week       int  `json:"week"`
+	HomeTeamID int  `json:"home_team_id"`
+	AwayTeamID int  `json:"away_team_id"`
+	HomeGoals  *int `json:"home_goals"`
+	AwayGoals  *int `json:"away_goals"`
+}
+
+// POST /matches?season=1 sezona fikstür dışı bir maç ekler. Skorla eklenen maç oynanmış
+// sayılır ve tablo yeniden hesaplanır; skorsuz maç haftası simüle edildiğinde oynanır
+func (h *MatchHandler) CreateMatchHandler(w http.ResponseWriter, r *http.Request) {
+	var req createMatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.Week < 1 {
+		http.Error(w, "'week' must be a positive integer", http.StatusBadRequest)
+		return
+	}
+
+	seasonID, err := seasonFromRequest(h.seasons, r)
+	if err != nil {
+		writeError(w, "Failed to create match", err)
+		return
+	}
+
+	match, err := h.simulator.AddMatch(seasonID, req.Week, req.HomeTeamID, req.AwayTeamID, req.HomeGoals, req.AwayGoals)
+	if err != nil {
+		writeError(w, "Failed to create match", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(match)
+}
+
+// /simulate/week endpointi, isteğe bağlı "week" parametresi alır (query param)
+// Hafta verilmezse sezonun sıradaki haftası oynanır. İsteğe bağlı "seed" ile sonuçlar tekrar üretilebilir.
+// Oynanan maçları, güncel puan tablosunu ve hafta öncesi tahminleri JSON olarak döner
+func (h *MatchHandler) SimulateWeekHandler(w http.ResponseWriter, r *http.Request) {
+	week := 0
+	if weekStr := r.URL.Query().Get("week"); weekStr != "" {
+		n, err := strconv.Atoi(weekStr)
+		if err != nil || n < 1 {
 			http.Error(w, "'week' must be a positive integer", http.StatusBadRequest)
 			return
 		}
-		matches, err = h.simulator.GetMatchesByWeek(week)
+		week = n
 	}
 
+	seed, err := parseSeed(r)
 	if err != nil {
-		http.Error(w, "Failed to fetch matches: "+err.Error(), http.StatusInternalServerError)
+		http.Error(w, "'seed' must be an integer", http.StatusBadRequest)
+		return
+	}
+
+	seasonID, err := seasonFromRequest(h.seasons, r)
+	if err != nil {
+		writeError(w, "Failed to simulate week, no changes were saved", err)
+		return
+	}
+
+	var result *services.WeekResult
+	if week == 0 {
+		result, err = h.simulator.SimulateNextWeek(seasonID, seed)
+	} else {
+		result, err = h.simulator.SimulateWeek(seasonID, week, seed)
+	}
+	if err != nil {
+		writeError(w, "Failed to simulate week, no changes were saved", err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(matches)
+	json.NewEncoder(w).Encode(result)
 }
 
+// /simulate/week/{week}/replay endpointi oynanmış bir haftanın sonuçlarını silip
+// aynı eşleşmeleri yeniden simüle eder. İşlem atomiktir; hata olursa eski sonuçlar korunur.
+func (h *MatchHandler) ReplayWeekHandler(w http.ResponseWriter, r *http.Request) {
+	week, err := strconv.Atoi(mux.Vars(r)["week"])
+	if err != nil || week < 1 {
+		http.Error(w, "'week' must be a positive integer", http.StatusBadRequest)
+		return
+	}
+
+	seed, err := parseSeed(r)
+	if err != nil {
+		http.Error(w, "'seed' must be an integer", http.StatusBadRequest)
+		return
+	}
 
+	seasonID, err := seasonFromRequest(h.seasons, r)
+	if err != nil {
+		writeError(w, "Failed to replay week", err)
+		return
+	}
+
+	result, err := h.simulator.ReplayWeek(seasonID, week, seed)
+	if err != nil {
+		writeError(w, "Failed to replay week, previous results were kept", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// /simulate/all endpointi sezonun kalan tüm haftalarını simüle eder ve oynanan
+// maçları ve sezon sonu tablosunu JSON olarak döner.
+// İsteğe bağlı "seed" verilirse haftaların tohumları bu değerden türetilir
+func (h *MatchHandler) SimulateAllHandler(w http.ResponseWriter, r *http.Request) {
+	seed, err := parseSeed(r)
+	if err != nil {
+		http.Error(w, "'seed' must be an integer", http.StatusBadRequest)
+		return
+	}
+
+	seasonID, err := seasonFromRequest(h.seasons, r)
+	if err != nil {
+		writeError(w, "Failed to simulate all weeks, the failing week was not saved", err)
+		return
+	}
+
+	summary, err := h.simulator.SimulateAllWeeks(seasonID, seed)
+	if err != nil {
+		writeError(w, "Failed to simulate all weeks, the failing week was not saved", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(summary)
+}
@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"insider-case/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+type SeasonHandler struct {
+	seasons *services.SeasonService
+}
+
+func NewSeasonHandler(db *sql.DB) *SeasonHandler {
+	return &SeasonHandler{seasons: services.NewSeasonService(db)}
+}
+
+// GET /seasons tüm sezonları listeler
+func (h *SeasonHandler) ListSeasonsHandler(w http.ResponseWriter, r *http.Request) {
+	seasons, err := h.seasons.List()
+	if err != nil {
+		http.Error(w, "Failed to list seasons: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(seasons)
+}
+
+// POST /seasons yeni bir sezon açar, gövde isteğe bağlı:
+// {"name": "2025/26", "tiebreakers": ["head_to_head_points"], "engine": "dixon_coles"}
+// Yeni sezon güncel sezon olur ve fikstürü hemen oluşturulur
+func (h *SeasonHandler) CreateSeasonHandler(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Name        string   `json:"name"`
+		Tiebreakers []string `json:"tiebreakers"`
+		Engine      string   `json:"engine"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, "Invalid JSON body: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	season, err := h.seasons.Create(body.Name, body.Tiebreakers, body.Engine)
+	if err != nil {
+		writeError(w, "Failed to create season", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(season)
+}
+
+// GET /seasons/{id} tek bir sezonu döner
+func (h *SeasonHandler) GetSeasonHandler(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+	season, err := h.seasons.Get(id)
+	if err != nil {
+		writeError(w, "Failed to get season", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(season)
+}
+
+// POST /seasons/{id}/archive sezonu arşivler; maçları ve tablosu saklanır ama simüle edilemez
+func (h *SeasonHandler) ArchiveSeasonHandler(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+	season, err := h.seasons.Archive(id)
+	if err != nil {
+		writeError(w, "Failed to archive season", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(season)
+}
+
+// PUT /seasons/{id}/tiebreakers sezonun averaj kurallarını değiştirir,
+// gövde: {"tiebreakers": ["head_to_head_points", "goal_difference"], "lots_seed": 42}
+// lots_seed isteğe bağlıdır, verilmezse mevcut kura tohumu korunur
+func (h *SeasonHandler) SetTiebreakersHandler(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+	var body struct {
+		Tiebreakers []string `json:"tiebreakers"`
+		LotsSeed    *int64   `json:"lots_seed"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid JSON body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if body.Tiebreakers == nil {
+		http.Error(w, "'tiebreakers' is required", http.StatusBadRequest)
+		return
+	}
+
+	season, err := h.seasons.SetTiebreakers(id, body.Tiebreakers, body.LotsSeed)
+	if err != nil {
+		writeError(w, "Failed to update tiebreakers", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(season)
+}
+
+// PUT /seasons/{id}/params sezonun simülasyon parametrelerini değiştirir, gövde sadece
+// değişen alanlar: {"home_advantage": 1.5, "cap_goals": false}. {} yapılandırmaya döndürür
+func (h *SeasonHandler) SetParamsHandler(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+	var body json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid JSON body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	season, err := h.seasons.SetParams(id, body)
+	if err != nil {
+		writeError(w, "Failed to update simulation parameters", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(season)
+}
+
+// PUT /seasons/{id}/engine sezonun skor modelini değiştirir, gövde: {"engine": "bivariate_poisson"}
+func (h *SeasonHandler) SetEngineHandler(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+	var body struct {
+		Engine string `json:"engine"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid JSON body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	season, err := h.seasons.SetEngine(id, body.Engine)
+	if err != nil {
+		writeError(w, "Failed to update match engine", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(season)
+}
@@ -1,55 +1,236 @@
-package router
+package handlers
 
 import (
 	"database/sql"
 	"encoding/json"
 	"insider-case/services"
 	"net/http"
+	"strconv"
+	"strings"
 )
 
 type TableHandler struct {
 	simulator *services.SimulatorService
+	fixtures  *services.FixtureService
+	seasons   *services.SeasonService
 }
 
 func NewTableHandler(db *sql.DB) *TableHandler {
 	return &TableHandler{
 		simulator: services.NewSimulatorService(db),
+		fixtures:  services.NewFixtureService(db),
+		seasons:   services.NewSeasonService(db),
 	}
 }
 
-// GET /standings
-// Güncel puan tablosunu JSON formatında döner
+// /standings endpointi güncel puan tablosunu JSON formatında döner.
+// İsteğe bağlı "week" verilirse tablo o haftanın sonundaki haliyle maçlardan yeniden hesaplanır,
+// "view" ile sadece iç saha (home) ya da deplasman (away) maçlarının tablosu alınabilir.
+// Her satırda son 5 maçın formu bulunur; "include=form" form_guide ile son "form_length" maçın formunu ("WWDLW") ve serileri ekler
 func (h *TableHandler) StandingsHandler(w http.ResponseWriter, r *http.Request) {
-	standings, err := h.simulator.GetCurrentStandings()
+	includeForm := false
+	if include := r.URL.Query().Get("include"); include != "" {
+		for _, part := range strings.Split(include, ",") {
+			if strings.TrimSpace(part) != "form" {
+				http.Error(w, "'include' only supports: form", http.StatusBadRequest)
+				return
+			}
+			includeForm = true
+		}
+	}
+
+	formLength := 0
+	if v := r.URL.Query().Get("form_length"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			http.Error(w, "'form_length' must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		formLength = n
+	}
+
+	week := 0
+	if weekStr := r.URL.Query().Get("week"); weekStr != "" {
+		n, err := strconv.Atoi(weekStr)
+		if err != nil || n < 1 {
+			http.Error(w, "'week' must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		week = n
+	}
+
+	seasonID, err := seasonFromRequest(h.seasons, r)
 	if err != nil {
-		http.Error(w, "Failed to get standings: "+err.Error(), http.StatusInternalServerError)
+		writeError(w, "Failed to get standings", err)
 		return
 	}
 
+	view := r.URL.Query().Get("view")
+	if view == "" {
+		view = services.ViewOverall
+	}
+
+	standings, err := h.simulator.GetStandings(seasonID, week, view)
+	if err != nil {
+		writeError(w, "Failed to get standings", err)
+		return
+	}
+	if includeForm {
+		services.AddFormGuides(standings, formLength)
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(standings)
 }
 
-func ResetTable(db *sql.DB) error {
-	// Tüm maçları sil
-	_, err := db.Exec(`DELETE FROM matches`)
+// GET /standings/history sezonun her takım için hafta hafta sıralamasını döner (grafik için)
+func (h *TableHandler) StandingsHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	seasonID, err := seasonFromRequest(h.seasons, r)
+	if err != nil {
+		writeError(w, "Failed to get standings history", err)
+		return
+	}
+
+	history, err := h.simulator.GetPositionHistory(seasonID)
+	if err != nil {
+		http.Error(w, "Failed to get standings history: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(history)
+}
+
+// GET /league/state sezonun güncel haftasını, toplam ve kalan hafta sayısını döner
+func (h *TableHandler) LeagueStateHandler(w http.ResponseWriter, r *http.Request) {
+	seasonID, err := seasonFromRequest(h.seasons, r)
 	if err != nil {
-		return err
+		writeError(w, "Failed to get league state", err)
+		return
 	}
 
-	// Takım skorlarını sıfırla (id, name, strength hariç)
-	_, err = db.Exec(`
-		UPDATE teams SET 
-			played = 0, 
-			won = 0, 
-			drawn = 0, 
-			lost = 0, 
-			points = 0,
-			goal_difference = 0
-	`)
+	state, err := h.simulator.GetLeagueState(seasonID)
 	if err != nil {
-		return err
+		http.Error(w, "Failed to get league state: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(state)
+}
+
+// /predictions/championship endpointi kalan maçları Monte Carlo ile simüle ederek
+// şampiyonluk, ilk N ve bitiş sırası olasılıklarını döner.
+// İsteğe bağlı parametreler: iterations (varsayılan 10000), top (varsayılan 2), seed
+func (h *TableHandler) ChampionshipPredictionHandler(w http.ResponseWriter, r *http.Request) {
+	iterations := 10000
+	if v := r.URL.Query().Get("iterations"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 1000000 {
+			http.Error(w, "'iterations' must be an integer between 1 and 1000000", http.StatusBadRequest)
+			return
+		}
+		iterations = n
+	}
+
+	top := 2
+	if v := r.URL.Query().Get("top"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			http.Error(w, "'top' must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		top = n
+	}
+
+	seed, err := parseSeed(r)
+	if err != nil {
+		http.Error(w, "'seed' must be an integer", http.StatusBadRequest)
+		return
+	}
+
+	seasonID, err := seasonFromRequest(h.seasons, r)
+	if err != nil {
+		writeError(w, "Failed to predict championship", err)
+		return
+	}
+
+	prediction, err := h.simulator.PredictChampionship(seasonID, iterations, top, seed)
+	if err != nil {
+		http.Error(w, "Failed to predict championship: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(prediction)
+}
+
+// GET /predictions/match?home=1&away=2 iki takım arasındaki maçın galibiyet/beraberlik/mağlubiyet
+// olasılıklarını, beklenen golleri ve skor olasılık matrisini sezonun skor modelinden hesaplar
+func (h *TableHandler) MatchPredictionHandler(w http.ResponseWriter, r *http.Request) {
+	homeID, err := strconv.Atoi(r.URL.Query().Get("home"))
+	if err != nil || homeID < 1 {
+		http.Error(w, "'home' must be a team ID", http.StatusBadRequest)
+		return
+	}
+	awayID, err := strconv.Atoi(r.URL.Query().Get("away"))
+	if err != nil || awayID < 1 {
+		http.Error(w, "'away' must be a team ID", http.StatusBadRequest)
+		return
+	}
+
+	seasonID, err := seasonFromRequest(h.seasons, r)
+	if err != nil {
+		writeError(w, "Failed to predict match", err)
+		return
+	}
+
+	prediction, err := h.simulator.PredictMatch(seasonID, homeID, awayID)
+	if err != nil {
+		writeError(w, "Failed to predict match", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(prediction)
+}
+
+// /reset endpointi sezonun (varsayılan: güncel sezon) maçlarını silip fikstürü yeniden oluşturur
+func (h *TableHandler) ResetHandler(w http.ResponseWriter, r *http.Request) {
+	seasonID, err := seasonFromRequest(h.seasons, r)
+	if err != nil {
+		writeError(w, "Failed to reset season", err)
+		return
+	}
+
+	if err := h.seasons.Reset(seasonID); err != nil {
+		writeError(w, "Failed to reset season", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("Database reset successfully"))
+}
+
+// /fixtures/generate endpointi mevcut takımlarla çift devreli fikstürü yeniden oluşturur.
+// Oynanmış maç varsa fikstür kilitlidir ve 409 döner.
+func (h *TableHandler) GenerateFixturesHandler(w http.ResponseWriter, r *http.Request) {
+	seasonID, err := seasonFromRequest(h.seasons, r)
+	if err != nil {
+		writeError(w, "Failed to generate fixtures", err)
+		return
+	}
+
+	if err := h.seasons.RequireActive(seasonID); err != nil {
+		writeError(w, "Failed to generate fixtures", err)
+		return
+	}
+
+	if err := h.fixtures.RegenerateSchedule(seasonID); err != nil {
+		writeError(w, "Failed to generate fixtures", err)
+		return
 	}
 
-	return nil
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("Fixtures generated successfully"))
 }
@@ -0,0 +1,249 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"insider-case/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+type TeamHandler struct {
+	teams       *services.TeamService
+	seasons     *services.SeasonService
+	calibration *services.CalibrationService
+}
+
+func NewTeamHandler(db *sql.DB) *TeamHandler {
+	return &TeamHandler{
+		teams:       services.NewTeamService(db),
+		seasons:     services.NewSeasonService(db),
+		calibration: services.NewCalibrationService(db),
+	}
+}
+
+// teamRequest takım oluşturma ve güncelleme isteklerinin gövdesi.
+// PATCH isteklerinde gönderilmeyen alanlar değişmez; POST ve PUT isteklerinde
+// attack ve defence verilmezse strength değerini alır.
+type teamRequest struct {
+	Name     *string `json:"name"`
+	Strength *int    `json:"strength"`
+	Attack   *int    `json:"attack"`
+	Defence  *int    `json:"defence"`
+}
+
+// attackDefence istekteki saldırı ve savunma güçleri, verilmeyenler için fallback
+func (b teamRequest) attackDefence(fallbackAttack, fallbackDefence int) (int, int) {
+	attack, defence := fallbackAttack, fallbackDefence
+	if b.Attack != nil {
+		attack = *b.Attack
+	}
+	if b.Defence != nil {
+		defence = *b.Defence
+	}
+	return attack, defence
+}
+
+// GET /teams tüm takımları döner
+func (h *TeamHandler) ListTeamsHandler(w http.ResponseWriter, r *http.Request) {
+	teams, err := h.teams.List()
+	if err != nil {
+		http.Error(w, "Failed to list teams: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(teams)
+}
+
+// GET /teams/{id} tek bir takımı döner
+func (h *TeamHandler) GetTeamHandler(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+	team, err := h.teams.Get(id)
+	if err != nil {
+		writeError(w, "Failed to get team", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(team)
+}
+
+// POST /teams yeni takım ekler, gövde: {"name": "Tottenham", "strength": 75, "attack": 80, "defence": 70}
+func (h *TeamHandler) CreateTeamHandler(w http.ResponseWriter, r *http.Request) {
+	var body teamRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid JSON body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if body.Name == nil || body.Strength == nil {
+		http.Error(w, "'name' and 'strength' are required", http.StatusBadRequest)
+		return
+	}
+
+	attack, defence := body.attackDefence(*body.Strength, *body.Strength)
+	team, err := h.teams.Create(*body.Name, *body.Strength, attack, defence)
+	if err != nil {
+		writeError(w, "Failed to create team", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(team)
+}
+
+// PUT /teams/{id} takımın tüm alanlarını, PATCH /teams/{id} sadece gönderilen alanları günceller
+func (h *TeamHandler) UpdateTeamHandler(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+	var body teamRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid JSON body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	existing, err := h.teams.Get(id)
+	if err != nil {
+		writeError(w, "Failed to update team", err)
+		return
+	}
+
+	if r.Method == http.MethodPut && (body.Name == nil || body.Strength == nil) {
+		http.Error(w, "'name' and 'strength' are required", http.StatusBadRequest)
+		return
+	}
+	name, strength := existing.Name, existing.Strength
+	if body.Name != nil {
+		name = *body.Name
+	}
+	if body.Strength != nil {
+		strength = *body.Strength
+	}
+	attack, defence := body.attackDefence(existing.Attack, existing.Defence)
+	if r.Method == http.MethodPut {
+		attack, defence = body.attackDefence(strength, strength)
+	}
+
+	team, err := h.teams.Update(id, name, strength, attack, defence)
+	if err != nil {
+		writeError(w, "Failed to update team", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(team)
+}
+
+// DELETE /teams/{id} takımı siler; oynanmış maçı olan takımlar silinemez (409)
+func (h *TeamHandler) DeleteTeamHandler(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+	if err := h.teams.Delete(id); err != nil {
+		writeError(w, "Failed to delete team", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// PUT /teams/{id}/fair-play takımın sezondaki disiplin puanını günceller, gövde: {"fair_play": 4}.
+// Sezon "season" parametresiyle seçilir, yoksa güncel sezon kullanılır.
+func (h *TeamHandler) SetFairPlayHandler(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+	var body struct {
+		FairPlay *int `json:"fair_play"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid JSON body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if body.FairPlay == nil {
+		http.Error(w, "'fair_play' is required", http.StatusBadRequest)
+		return
+	}
+
+	seasonID, err := seasonFromRequest(h.seasons, r)
+	if err != nil {
+		writeError(w, "Failed to update fair play points", err)
+		return
+	}
+
+	team, err := h.teams.SetFairPlay(seasonID, id, *body.FairPlay)
+	if err != nil {
+		writeError(w, "Failed to update fair play points", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(team)
+}
+
+// GET /teams/{id}/head-to-head/{otherId} iki takımın tüm sezonlardaki karşılaşmalarını,
+// galibiyet/beraberlik/mağlubiyet özetini, iç saha/deplasman dağılımını ve en farklı skorları döner
+func (h *TeamHandler) HeadToHeadHandler(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	otherID, _ := strconv.Atoi(mux.Vars(r)["otherId"])
+
+	h2h, err := h.teams.HeadToHead(id, otherID)
+	if err != nil {
+		writeError(w, "Failed to get head-to-head record", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h2h)
+}
+
+// GET /teams/{id}/ratings takımın sezondaki hafta hafta Elo puanlarını döner
+func (h *TeamHandler) RatingsHandler(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+	seasonID, err := seasonFromRequest(h.seasons, r)
+	if err != nil {
+		writeError(w, "Failed to get ratings", err)
+		return
+	}
+
+	history, err := h.teams.RatingHistory(id, seasonID)
+	if err != nil {
+		writeError(w, "Failed to get ratings", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(history)
+}
+
+// POST /teams/calibrate takımların saldırı/savunma güçlerini ve ev sahibi avantajını geçmiş
+// sonuçlardan tahmin edip yazar. Gövde isteğe bağlı:
+// {"seasons": [1, 2], "results": [{"home_team_id": 1, "away_team_id": 2, "home_goals": 2, "away_goals": 1}], "dry_run": true}
+// Ev sahibi avantajı ve temel gol oranı "season" parametresindeki (yoksa güncel) sezona yazılır
+func (h *TeamHandler) CalibrateHandler(w http.ResponseWriter, r *http.Request) {
+	var body services.CalibrationRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, "Invalid JSON body: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	seasonID, err := seasonFromRequest(h.seasons, r)
+	if err != nil {
+		writeError(w, "Failed to calibrate teams", err)
+		return
+	}
+
+	calibration, err := h.calibration.Calibrate(seasonID, body)
+	if err != nil {
+		writeError(w, "Failed to calibrate teams", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(calibration)
+}
@@ -3,19 +3,42 @@ package main
 import (
 	"database/sql"
 	"insider-case/router" // router klasörünü import et
+	"insider-case/services"
 	"log"
 	"net/http"
+	"os"
 
 	_ "github.com/mattn/go-sqlite3"
 )
 
+// defaultSimulationConfig SIMULATION_CONFIG verilmediğinde, varsa okunan parametre dosyası
+const defaultSimulationConfig = "./simulation.json"
+
 func main() {
+	configPath := os.Getenv("SIMULATION_CONFIG")
+	if configPath == "" {
+		if _, err := os.Stat(defaultSimulationConfig); err == nil {
+			configPath = defaultSimulationConfig
+		}
+	}
+	params, err := services.LoadSimulationParams(configPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := services.SetSimulationParams(params); err != nil {
+		log.Fatal(err)
+	}
+
 	db, err := sql.Open("sqlite3", "./league.db")
 	if err != nil {
 		log.Fatal(err)
 	}
 	defer db.Close()
 
+	if err := services.Migrate(db); err != nil {
+		log.Fatal(err)
+	}
+
 	router := router.NewRouter(db)
 	http.ListenAndServe(":8080", router.SetupRoutes())
 }
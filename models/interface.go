@@ -1,10 +1,14 @@
 package models
 
-// Simulator defines simulator servisinin dışarıya sunduğu davranışları belirtir.
-type Simulator interface {
-	SimulateWeek(week int) error
-	SimulateAllWeeks() error
-	GetCurrentStandings() ([]Team, error)
-	GetAllMatches() ([]Match, error)
-	GetMatchesByWeek(week int) ([]Match, error)
+import "math/rand"
+
+// MatchEngine iki takımın saldırı ve savunma güçlerinden maç skoru üreten skor modelidir.
+// Simülasyon, Monte Carlo tahminleri ve maç tahmini sadece bu arayüzü kullanır.
+type MatchEngine interface {
+	// Name sezon ayarlarında kullanılan model adı
+	Name() string
+	// SimulateScore rng ile bir skor üretir; aynı tohumlu rng aynı skoru verir
+	SimulateScore(home, away TeamStrength, rng *rand.Rand) (homeGoals, awayGoals int)
+	// ScoreProbabilities skor olasılık matrisi, [ev sahibi golü][deplasman golü]
+	ScoreProbabilities(home, away TeamStrength) [][]float64
 }
@@ -1,5 +1,51 @@
 package models
 
+import "fmt"
+
+// Result oynanmış bir maçın ev sahibine göre sonucu; matches.result kolonunda bu değerler tutulur
+type Result string
+
+const (
+	ResultHomeWin Result = "HOME_WIN"
+	ResultAwayWin Result = "AWAY_WIN"
+	ResultDraw    Result = "DRAW"
+)
+
+// ResultFromScore skordan maç sonucunu hesaplar
+func ResultFromScore(homeGoals, awayGoals int) Result {
+	switch {
+	case homeGoals > awayGoals:
+		return ResultHomeWin
+	case homeGoals < awayGoals:
+		return ResultAwayWin
+	default:
+		return ResultDraw
+	}
+}
+
+// Scan matches.result kolonunu okur; oynanmamış maçlardaki NULL boş sonuç olur
+func (r *Result) Scan(src any) error {
+	var s string
+	switch v := src.(type) {
+	case nil:
+		*r = ""
+		return nil
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	default:
+		return fmt.Errorf("unsupported match result type %T", src)
+	}
+
+	switch Result(s) {
+	case ResultHomeWin, ResultAwayWin, ResultDraw:
+		*r = Result(s)
+		return nil
+	}
+	return fmt.Errorf("invalid match result %q", s)
+}
+
 type Match struct {
 	ID         int    // Maç ID
 	Week       int    // Haftası
@@ -7,5 +53,6 @@ type Match struct {
 	AwayTeamID int    // Deplasman takımının ID'si
 	HomeGoals  int    // Ev sahibi takımın attığı gol sayısı
 	AwayGoals  int    // Deplasman takımının attığı gol sayısı
-	Result     string // "HomeWin", "AwayWin", "Draw" - Opsiyonel, hesaplanabilir
+	Result     Result // HOME_WIN, AWAY_WIN, DRAW - oynanmamış fikstürde boş
+	Played     bool   // Oynandı mı, false ise henüz oynanmamış fikstür
 }
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	SeasonActive   = "active"   // Simülasyona açık sezon
+	SeasonArchived = "archived" // Arşivlenmiş, sadece okunabilir sezon
+)
+
+type Season struct {
+	ID         int        `json:"id"`
+	Name       string     `json:"name"`
+	Status     string     `json:"status"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ArchivedAt *time.Time `json:"archived_at,omitempty"`
+	// Puanı eşit takımlara sırayla uygulanan kurallar, örn. ["head_to_head_points", "goal_difference"]
+	Tiebreakers []string `json:"tiebreakers"`
+	LotsSeed    int64    `json:"lots_seed"` // Kura çekiminin sabit tohumu
+	Engine      string   `json:"engine"`    // Skor modeli: poisson, dixon_coles, bivariate_poisson
+	// Sezonda yapılandırmadan farklı verilen simülasyon parametreleri, örn. {"home_advantage": 1.5}
+	ParamOverrides json.RawMessage  `json:"param_overrides"`
+	Params         SimulationParams `json:"params"` // Sezonda kullanılan parametrelerin tamamı
+}
@@ -0,0 +1,24 @@
+package models
+
+// TeamStrength skor modeline verilen takım gücü, çarpan olarak. Bir takımın gol ortalaması
+// kendi Attack'ı ile rakibinin Defence'ı çarpılarak bulunur.
+type TeamStrength struct {
+	Attack  float64 `json:"attack"`  // saldırı / StrengthScale
+	Defence float64 `json:"defence"` // lig ortalama savunması / savunma; ortalama savunma 1, iyi savunma 1'in altında
+}
+
+// SimulationParams skor modelinin ayarlanabilir değerleri. Takımların gol ortalaması:
+// ev sahibi = BaseGoalRate * HomeAdvantage * ev.Attack * deplasman.Defence,
+// deplasman = BaseGoalRate * deplasman.Attack * ev.Defence
+type SimulationParams struct {
+	HomeAdvantage       float64 `json:"home_advantage"`       // Ev sahibi gol çarpanı
+	BaseGoalRate        float64 `json:"base_goal_rate"`       // Saldırısı StrengthScale olan takımın ortalama savunmaya karşı deplasman gol ortalaması
+	StrengthScale       float64 `json:"strength_scale"`       // Saldırı gücünün normalize edildiği değer
+	CapGoals            bool    `json:"cap_goals"`            // Goller MaxGoals ile sınırlansın mı
+	MaxGoals            int     `json:"max_goals"`            // Bir takımın bir maçta atabileceği en fazla gol
+	DixonColesRho       float64 `json:"dixon_coles_rho"`      // dixon_coles modelinin düşük skor düzeltmesi
+	BivariateCovariance float64 `json:"bivariate_covariance"` // bivariate_poisson modelinin ortak gol ortalaması
+	Ratings             bool    `json:"ratings"`              // Skor modeline sabit güç yerine Elo puanından gelen güç verilsin mi
+	EloK                float64 `json:"elo_k"`                // Elo güncellemesinin katsayısı
+	EloHomeAdvantage    float64 `json:"elo_home_advantage"`   // Beklenen sonuç hesaplanırken ev sahibine eklenen puan
+}
@@ -13,4 +13,7 @@ type Team struct {
 	GD       int    `json:"gd"`
 	Points   int    `json:"points"`
 	Strength int    `json:"strength"`
+	Attack   int    `json:"attack"`    // Gol atma gücü
+	Defence  int    `json:"defence"`   // Gol yememe gücü, yüksek olan daha az gol yer
+	FairPlay int    `json:"fair_play"` // Sezondaki disiplin puanı (kartlar), az olan daha iyi
 }
@@ -2,110 +2,62 @@ package router
 
 import (
 	"database/sql"
-	"encoding/json"
-	"insider-case/services"
+	"insider-case/handlers"
 	"net/http"
-	"strconv"
 
 	"github.com/gorilla/mux"
 )
 
+// Router HTTP rotalarını handlers paketindeki handler'lara bağlar
 type Router struct {
-	db        *sql.DB
-	simulator *services.SimulatorService
+	matches *handlers.MatchHandler
+	table   *handlers.TableHandler
+	seasons *handlers.SeasonHandler
+	teams   *handlers.TeamHandler
 }
 
 func NewRouter(db *sql.DB) *Router {
 	return &Router{
-		db:        db,
-		simulator: services.NewSimulatorService(db),
+		matches: handlers.NewMatchHandler(db),
+		table:   handlers.NewTableHandler(db),
+		seasons: handlers.NewSeasonHandler(db),
+		teams:   handlers.NewTeamHandler(db),
 	}
 }
 
 func (r *Router) SetupRoutes() http.Handler {
 	mux := mux.NewRouter()
 
-	mux.HandleFunc("/simulate/week", r.SimulateWeekHandler).Methods("POST")
-	mux.HandleFunc("/simulate/all", r.SimulateAllHandler).Methods("POST")
-	mux.HandleFunc("/standings", r.StandingsHandler).Methods("GET")
-	mux.HandleFunc("/reset", r.ResetHandler).Methods("POST")
+	mux.HandleFunc("/matches", r.matches.ListMatchesHandler).Methods("GET")
+	mux.HandleFunc("/matches/{id:[0-9]+}", r.matches.GetMatchHandler).Methods("GET")
+	mux.HandleFunc("/matches", r.matches.CreateMatchHandler).Methods("POST")
+	mux.HandleFunc("/matches/{id:[0-9]+}/result", r.matches.SetResultHandler).Methods("PUT")
+	mux.HandleFunc("/simulate/week", r.matches.SimulateWeekHandler).Methods("POST")
+	mux.HandleFunc("/simulate/week/{week:[0-9]+}/replay", r.matches.ReplayWeekHandler).Methods("POST")
+	mux.HandleFunc("/simulate/all", r.matches.SimulateAllHandler).Methods("POST")
+	mux.HandleFunc("/standings", r.table.StandingsHandler).Methods("GET")
+	mux.HandleFunc("/standings/history", r.table.StandingsHistoryHandler).Methods("GET")
+	mux.HandleFunc("/league/state", r.table.LeagueStateHandler).Methods("GET")
+	mux.HandleFunc("/predictions/championship", r.table.ChampionshipPredictionHandler).Methods("GET")
+	mux.HandleFunc("/predictions/match", r.table.MatchPredictionHandler).Methods("GET")
+	mux.HandleFunc("/reset", r.table.ResetHandler).Methods("POST")
+	mux.HandleFunc("/fixtures/generate", r.table.GenerateFixturesHandler).Methods("POST")
+	mux.HandleFunc("/seasons", r.seasons.ListSeasonsHandler).Methods("GET")
+	mux.HandleFunc("/seasons", r.seasons.CreateSeasonHandler).Methods("POST")
+	mux.HandleFunc("/seasons/{id:[0-9]+}", r.seasons.GetSeasonHandler).Methods("GET")
+	mux.HandleFunc("/seasons/{id:[0-9]+}/archive", r.seasons.ArchiveSeasonHandler).Methods("POST")
+	mux.HandleFunc("/seasons/{id:[0-9]+}/tiebreakers", r.seasons.SetTiebreakersHandler).Methods("PUT")
+	mux.HandleFunc("/seasons/{id:[0-9]+}/engine", r.seasons.SetEngineHandler).Methods("PUT")
+	mux.HandleFunc("/seasons/{id:[0-9]+}/params", r.seasons.SetParamsHandler).Methods("PUT")
+	mux.HandleFunc("/teams", r.teams.ListTeamsHandler).Methods("GET")
+	mux.HandleFunc("/teams", r.teams.CreateTeamHandler).Methods("POST")
+	mux.HandleFunc("/teams/calibrate", r.teams.CalibrateHandler).Methods("POST")
+	mux.HandleFunc("/teams/{id:[0-9]+}", r.teams.GetTeamHandler).Methods("GET")
+	mux.HandleFunc("/teams/{id:[0-9]+}", r.teams.UpdateTeamHandler).Methods("PUT", "PATCH")
+	mux.HandleFunc("/teams/{id:[0-9]+}", r.teams.DeleteTeamHandler).Methods("DELETE")
+	mux.HandleFunc("/teams/{id:[0-9]+}/fair-play", r.teams.SetFairPlayHandler).Methods("PUT")
+	mux.HandleFunc("/teams/{id:[0-9]+}/ratings", r.teams.RatingsHandler).Methods("GET")
+	mux.HandleFunc("/teams/{id:[0-9]+}/head-to-head/{otherId:[0-9]+}", r.teams.HeadToHeadHandler).Methods("GET")
 
 	return mux
 }
-func (r *Router) ResetHandler(w http.ResponseWriter, req *http.Request) {
-	_, err := r.db.Exec("DELETE FROM matches;")
-	if err != nil {
-		http.Error(w, "Failed to delete matches: "+err.Error(), http.StatusInternalServerError)
-		return
-	}
-
-	_, err = r.db.Exec(`
-		UPDATE teams
-		SET
-			position = 0,
-			played = 0,
-			won = 0,
-			drawn = 0,
-			lost = 0,
-			gf = 0,
-			ga = 0,
-			gd = 0,
-			points = 0;
-	`)
-	if err != nil {
-		http.Error(w, "Failed to reset teams: "+err.Error(), http.StatusInternalServerError)
-		return
-	}
-
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte("Database reset successfully"))
-}
-
-// /simulate/week endpointi, istekte "week" parametresi bekliyor (POST form veya query param)
-// İlgili haftayı simüle eder ve sonucu döner
-func (r *Router) SimulateWeekHandler(w http.ResponseWriter, req *http.Request) {
-	weekStr := req.URL.Query().Get("week")
-	if weekStr == "" {
-		http.Error(w, "Missing 'week' query parameter", http.StatusBadRequest)
-		return
-	}
-
-	week, err := strconv.Atoi(weekStr)
-	if err != nil || week < 1 {
-		http.Error(w, "'week' must be a positive integer", http.StatusBadRequest)
-		return
-	}
-
-	err = r.simulator.SimulateWeek(week)
-	if err != nil {
-		http.Error(w, "Failed to simulate week: "+err.Error(), http.StatusInternalServerError)
-		return
-	}
-
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte("Week " + weekStr + " simulated successfully"))
-}
-
-// /simulate/all endpointi tüm haftaları simüle eder (örneğin 1-5 hafta)
-func (r *Router) SimulateAllHandler(w http.ResponseWriter, req *http.Request) {
-	err := r.simulator.SimulateAllWeeks()
-	if err != nil {
-		http.Error(w, "Failed to simulate all weeks: "+err.Error(), http.StatusInternalServerError)
-		return
-	}
-
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte("All weeks simulated successfully"))
-}
-
-// /standings endpointi güncel puan tablosunu JSON formatında döner
-func (r *Router) StandingsHandler(w http.ResponseWriter, req *http.Request) {
-	standings, err := r.simulator.GetCurrentStandings()
-	if err != nil {
-		http.Error(w, "Failed to get standings: "+err.Error(), http.StatusInternalServerError)
-		return
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(standings)
-}
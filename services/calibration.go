@@ -0,0 +1,434 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+var ErrInvalidCalibration = errors.New("invalid calibration input")
+
+// Kalibrasyonun yakınsama ölçütü ve en fazla tekrar sayısı
+const (
+	calibrationTolerance     = 1e-9
+	calibrationMaxIterations = 1000
+)
+
+// CalibrationResult kalibrasyona verilen dışarıdan bir maç sonucu
+type CalibrationResult struct {
+	HomeTeamID int `json:"home_team_id"`
+	AwayTeamID int `json:"away_team_id"`
+	HomeGoals  int `json:"home_goals"`
+	AwayGoals  int `json:"away_goals"`
+}
+
+// CalibrationRequest kalibrasyonun girdisi. Results dışarıdan verilen sonuçlar, Seasons
+// maçları kullanılacak sezonlar; ikisi de boşsa tüm sezonların oynanmış maçları kullanılır.
+// DryRun ise sonuçlar hesaplanır ama yazılmaz.
+type CalibrationRequest struct {
+	Seasons []int               `json:"seasons"`
+	Results []CalibrationResult `json:"results"`
+	DryRun  bool                `json:"dry_run"`
+}
+
+// CalibratedTeam bir takımın eski ve tahmin edilen saldırı/savunma güçleri
+type CalibratedTeam struct {
+	TeamID          int    `json:"team_id"`
+	Name            string `json:"name"`
+	Matches         int    `json:"matches"`
+	PreviousAttack  int    `json:"previous_attack"`
+	PreviousDefence int    `json:"previous_defence"`
+	Attack          int    `json:"attack"`
+	Defence         int    `json:"defence"`
+}
+
+// Calibration en çok olabilirlik tahmininin sonucu. HomeAdvantage ve BaseGoalRate
+// SeasonID'li sezonun parametrelerine yazılır.
+type Calibration struct {
+	SeasonID      int              `json:"season_id"`
+	Matches       int              `json:"matches"`
+	Iterations    int              `json:"iterations"`
+	LogLikelihood float64          `json:"log_likelihood"`
+	HomeAdvantage float64          `json:"home_advantage"`
+	BaseGoalRate  float64          `json:"base_goal_rate"`
+	Applied       bool             `json:"applied"`
+	Teams         []CalibratedTeam `json:"teams"`
+}
+
+type CalibrationService struct {
+	DB      *sql.DB
+	seasons *SeasonService
+	teams   *TeamService
+}
+
+func NewCalibrationService(db *sql.DB) *CalibrationService {
+	return &CalibrationService{DB: db, seasons: NewSeasonService(db), teams: NewTeamService(db)}
+}
+
+// calibrationTeam tahmin sırasında bir takımın verisi. attackRate gol atma, defenceRate
+// gol yeme çarpanıdır (büyük olan daha çok gol yer).
+type calibrationTeam struct {
+	id, strength, attack, defence int
+	name                          string
+	matches                       int
+	scored, conceded              float64
+	attackRate, defenceRate       float64
+	fittedAttack, fittedDefence   int
+}
+
+// calibrationMatch kalibrasyonda kullanılan bir maç sonucu
+type calibrationMatch struct {
+	home, away, homeGoals, awayGoals int
+}
+
+// Calibrate takımların saldırı ve savunma güçlerini ve ligin ev sahibi avantajını, skor
+// modelinin bağımsız Poisson varsayımı altında en çok olabilirlik yöntemiyle tahmin eder.
+// Model: ev sahibi golü ~ Poisson(h * a_ev * d_dep), deplasman golü ~ Poisson(a_dep * d_ev).
+// Her parametre diğerleri sabitken kapalı formdaki en iyi değerine güncellenir; olabilirlik
+// her adımda artar ve göreli değişim calibrationTolerance altına inince durulur.
+// Tahminler güçlere çevrilip teams tablosuna, ev sahibi avantajı ve temel gol oranı da
+// seasonID'li sezonun parametrelerine yazılır. Gol tavanı tahminde dikkate alınmaz.
+func (c *CalibrationService) Calibrate(seasonID int, req CalibrationRequest) (*Calibration, error) {
+	if err := c.seasons.RequireActive(seasonID); err != nil {
+		return nil, err
+	}
+	params, err := seasonParams(c.DB, seasonID)
+	if err != nil {
+		return nil, err
+	}
+
+	teams, err := c.loadTeams()
+	if err != nil {
+		return nil, err
+	}
+	matches, err := c.loadResults(req, teams)
+	if err != nil {
+		return nil, err
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("%w: no results to fit", ErrInvalidCalibration)
+	}
+
+	homeGoals := 0.0
+	for _, m := range matches {
+		home, away := teams[m.home], teams[m.away]
+		home.matches++
+		away.matches++
+		home.scored += float64(m.homeGoals)
+		home.conceded += float64(m.awayGoals)
+		away.scored += float64(m.awayGoals)
+		away.conceded += float64(m.homeGoals)
+		homeGoals += float64(m.homeGoals)
+	}
+	if homeGoals == 0 {
+		return nil, fmt.Errorf("%w: home advantage cannot be estimated without home goals", ErrInvalidCalibration)
+	}
+	for _, t := range teams {
+		t.attackRate, t.defenceRate = 1, 1
+	}
+
+	homeAdvantage, iterations := 1.0, 0
+	for iterations < calibrationMaxIterations {
+		iterations++
+		change := 0.0
+
+		// Saldırı: atılan gol / saldırı çarpanı hariç beklenen gol
+		exposure := make(map[int]float64)
+		for _, m := range matches {
+			exposure[m.home] += homeAdvantage * teams[m.away].defenceRate
+			exposure[m.away] += teams[m.home].defenceRate
+		}
+		for id, t := range teams {
+			if t.matches > 0 && exposure[id] > 0 {
+				change = math.Max(change, update(&t.attackRate, t.scored/exposure[id]))
+			}
+		}
+
+		// Savunma: yenen gol / savunma çarpanı hariç beklenen gol
+		exposure = make(map[int]float64)
+		for _, m := range matches {
+			exposure[m.away] += homeAdvantage * teams[m.home].attackRate
+			exposure[m.home] += teams[m.away].attackRate
+		}
+		for id, t := range teams {
+			if t.matches > 0 && exposure[id] > 0 {
+				change = math.Max(change, update(&t.defenceRate, t.conceded/exposure[id]))
+			}
+		}
+
+		// Ev sahibi avantajı: ev sahiplerinin golü / ev avantajı hariç beklenen gol
+		expected := 0.0
+		for _, m := range matches {
+			expected += teams[m.home].attackRate * teams[m.away].defenceRate
+		}
+		change = math.Max(change, update(&homeAdvantage, homeGoals/expected))
+
+		if change < calibrationTolerance {
+			break
+		}
+	}
+
+	result := &Calibration{
+		SeasonID:      seasonID,
+		Matches:       len(matches),
+		Iterations:    iterations,
+		LogLikelihood: roundTo(logLikelihood(matches, teams, homeAdvantage), 4),
+		HomeAdvantage: roundTo(homeAdvantage, 4),
+		BaseGoalRate:  roundTo(toStrengths(teams, params.StrengthScale), 4),
+		Teams:         []CalibratedTeam{},
+	}
+	for _, t := range teams {
+		if t.matches == 0 {
+			continue
+		}
+		result.Teams = append(result.Teams, CalibratedTeam{
+			TeamID:          t.id,
+			Name:            t.name,
+			Matches:         t.matches,
+			PreviousAttack:  t.attack,
+			PreviousDefence: t.defence,
+			Attack:          t.fittedAttack,
+			Defence:         t.fittedDefence,
+		})
+	}
+	sort.Slice(result.Teams, func(i, j int) bool { return result.Teams[i].TeamID < result.Teams[j].TeamID })
+
+	if req.DryRun {
+		return result, nil
+	}
+	if err := c.apply(seasonID, result); err != nil {
+		return nil, err
+	}
+	result.Applied = true
+	return result, nil
+}
+
+// update değeri yenisiyle değiştirir ve göreli değişimi döner
+func update(value *float64, next float64) float64 {
+	change := math.Abs(next-*value) / math.Max(*value, 1e-12)
+	*value = next
+	return change
+}
+
+// logLikelihood maçların tahmin edilen çarpanlar altındaki Poisson log-olabilirliği
+func logLikelihood(matches []calibrationMatch, teams map[int]*calibrationTeam, homeAdvantage float64) float64 {
+	logPoisson := func(k int, lambda float64) float64 {
+		if lambda <= 0 {
+			if k == 0 {
+				return 0
+			}
+			return math.Inf(-1)
+		}
+		lgamma, _ := math.Lgamma(float64(k + 1))
+		return float64(k)*math.Log(lambda) - lambda - lgamma
+	}
+
+	total := 0.0
+	for _, m := range matches {
+		home, away := teams[m.home], teams[m.away]
+		total += logPoisson(m.homeGoals, homeAdvantage*home.attackRate*away.defenceRate)
+		total += logPoisson(m.awayGoals, away.attackRate*home.defenceRate)
+	}
+	return total
+}
+
+// toStrengths tahmin edilen çarpanları 1..MaxStrength aralığındaki saldırı ve savunma
+// güçlerine çevirir (fittedAttack, fittedDefence) ve modelin temel gol oranını döner.
+// Saldırı çarpanı kA ile çarpılarak, savunma gücü kD'nin gol yeme çarpanına bölünmesiyle
+// bulunur. Model saldırıyı strength_scale'e, savunmayı lig ortalamasına göre kullandığından
+// ölçekler serbesttir; ortalamaları takımların ortalama strength değerine eşit olacak,
+// MaxStrength'i aşmayacak şekilde seçilir. Hiç gol atmamış ya da yememiş takımlar sınıra oturur.
+func toStrengths(teams map[int]*calibrationTeam, scale float64) float64 {
+	var n, meanStrength, meanAttack, meanInverseDefence float64
+	var attacking, defending float64
+	maxAttack, minDefenceRate := 0.0, math.Inf(1)
+	for _, t := range teams {
+		if t.matches == 0 {
+			continue
+		}
+		n++
+		meanStrength += float64(t.strength)
+		if t.attackRate > 0 {
+			attacking++
+			meanAttack += t.attackRate
+			maxAttack = math.Max(maxAttack, t.attackRate)
+		}
+		if t.defenceRate > 0 {
+			defending++
+			meanInverseDefence += 1 / t.defenceRate
+			minDefenceRate = math.Min(minDefenceRate, t.defenceRate)
+		}
+	}
+	meanStrength /= n
+
+	kA, kD := 0.0, 0.0
+	if attacking > 0 {
+		kA = math.Min(meanStrength/(meanAttack/attacking), MaxStrength/maxAttack)
+	}
+	if defending > 0 {
+		kD = math.Min(meanStrength/(meanInverseDefence/defending), MaxStrength*minDefenceRate)
+	}
+
+	averageDefence := 0.0
+	for _, t := range teams {
+		t.fittedAttack, t.fittedDefence = t.attack, t.defence
+		if t.matches > 0 {
+			t.fittedAttack = clampStrength(int(math.Round(kA * t.attackRate)))
+			t.fittedDefence = MaxStrength
+			if t.defenceRate > 0 {
+				t.fittedDefence = clampStrength(int(math.Round(kD / t.defenceRate)))
+			}
+		}
+		averageDefence += float64(t.fittedDefence) / float64(len(teams))
+	}
+
+	// Model: base * (kA*a/scale) * (ortSavunma / (kD/d)) = a * d
+	if kA == 0 || averageDefence == 0 {
+		return 0
+	}
+	return scale * kD / (kA * averageDefence)
+}
+
+// roundTo değeri verilen ondalık basamağa yuvarlar
+func roundTo(value float64, digits int) float64 {
+	p := math.Pow(10, float64(digits))
+	return math.Round(value*p) / p
+}
+
+// loadTeams tüm takımları ID'lerine göre döner
+func (c *CalibrationService) loadTeams() (map[int]*calibrationTeam, error) {
+	rows, err := c.DB.Query("SELECT id, name, strength, attack, defence FROM teams")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	teams := make(map[int]*calibrationTeam)
+	for rows.Next() {
+		t := &calibrationTeam{}
+		if err := rows.Scan(&t.id, &t.name, &t.strength, &t.attack, &t.defence); err != nil {
+			return nil, err
+		}
+		teams[t.id] = t
+	}
+	return teams, rows.Err()
+}
+
+// loadResults dışarıdan verilen sonuçları doğrular ve istenen sezonların oynanmış maçlarını ekler
+func (c *CalibrationService) loadResults(req CalibrationRequest, teams map[int]*calibrationTeam) ([]calibrationMatch, error) {
+	var matches []calibrationMatch
+	for i, r := range req.Results {
+		_, home := teams[r.HomeTeamID]
+		_, away := teams[r.AwayTeamID]
+		switch {
+		case !home || !away:
+			return nil, fmt.Errorf("%w: result %d: unknown team", ErrInvalidCalibration, i)
+		case r.HomeTeamID == r.AwayTeamID:
+			return nil, fmt.Errorf("%w: result %d: home and away teams must differ", ErrInvalidCalibration, i)
+		case r.HomeGoals < 0 || r.AwayGoals < 0:
+			return nil, fmt.Errorf("%w: result %d: goals must not be negative", ErrInvalidCalibration, i)
+		}
+		matches = append(matches, calibrationMatch{r.HomeTeamID, r.AwayTeamID, r.HomeGoals, r.AwayGoals})
+	}
+
+	// Sadece dışarıdan sonuç verildiyse kayıtlı maçlar kullanılmaz
+	if len(req.Seasons) == 0 && len(req.Results) > 0 {
+		return matches, nil
+	}
+
+	query := "SELECT home_team_id, away_team_id, home_goals, away_goals FROM matches WHERE played = 1"
+	var args []any
+	if len(req.Seasons) > 0 {
+		for _, id := range req.Seasons {
+			if _, err := c.seasons.Get(id); err != nil {
+				return nil, err
+			}
+			args = append(args, id)
+		}
+		query += " AND season_id IN (?" + strings.Repeat(", ?", len(req.Seasons)-1) + ")"
+	}
+	rows, err := c.DB.Query(query+" ORDER BY season_id, week, id", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var m calibrationMatch
+		if err := rows.Scan(&m.home, &m.away, &m.homeGoals, &m.awayGoals); err != nil {
+			return nil, err
+		}
+		// Silinmiş takımların maçları atlanır
+		if _, ok := teams[m.home]; !ok {
+			continue
+		}
+		if _, ok := teams[m.away]; !ok {
+			continue
+		}
+		matches = append(matches, m)
+	}
+	return matches, rows.Err()
+}
+
+// apply tahmin edilen güçleri teams tablosuna, ev sahibi avantajı ve temel gol oranını
+// sezonun parametre değişikliklerine (diğer değişiklikler korunarak) tek transaction içinde yazar.
+// Güçler teams tablosunda tüm sezonlar için ortak olduğundan, herhangi bir aktif sezonda maç
+// oynamış bir takımın gücü değişecekse hiçbir şey yazılmaz ve ErrTeamHasMatches döner;
+// dry_run ile sonuç yine de görülebilir.
+func (c *CalibrationService) apply(seasonID int, result *Calibration) error {
+	for _, t := range result.Teams {
+		if t.Attack == t.PreviousAttack && t.Defence == t.PreviousDefence {
+			continue
+		}
+		played, err := c.teams.countActivePlayedMatches(t.TeamID)
+		if err != nil {
+			return err
+		}
+		if played > 0 {
+			return fmt.Errorf("team %d has played in an active season: %w", t.TeamID, ErrTeamHasMatches)
+		}
+	}
+
+	var stored string
+	if err := c.DB.QueryRow("SELECT sim_params FROM seasons WHERE id = ?", seasonID).Scan(&stored); err != nil {
+		return err
+	}
+	overrides := map[string]any{}
+	if err := json.Unmarshal([]byte(stored), &overrides); err != nil {
+		return err
+	}
+	overrides["home_advantage"] = result.HomeAdvantage
+	overrides["base_goal_rate"] = result.BaseGoalRate
+
+	encoded, err := json.Marshal(overrides)
+	if err != nil {
+		return err
+	}
+	params := configuredParams
+	if err := decodeParams(encoded, &params); err != nil {
+		return err
+	}
+	if err := ValidateSimulationParams(params); err != nil {
+		return fmt.Errorf("%w: fitted values are outside the allowed parameters: %v", ErrInvalidCalibration, err)
+	}
+
+	tx, err := c.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, t := range result.Teams {
+		if _, err := tx.Exec("UPDATE teams SET attack = ?, defence = ? WHERE id = ?", t.Attack, t.Defence, t.TeamID); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.Exec("UPDATE seasons SET sim_params = ? WHERE id = ?", string(encoded), seasonID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,186 @@
+package services
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// syntheticResults her eşleşmeyi rounds kez, verilen gol oranlarından Poisson ile oynatır
+func syntheticResults(attack, defence map[int]float64, homeAdvantage float64, rounds int, seed int64) []CalibrationResult {
+	rng := rand.New(rand.NewSource(seed))
+	var results []CalibrationResult
+	for r := 0; r < rounds; r++ {
+		for home := 1; home <= len(attack); home++ {
+			for away := 1; away <= len(attack); away++ {
+				if home == away {
+					continue
+				}
+				results = append(results, CalibrationResult{
+					HomeTeamID: home,
+					AwayTeamID: away,
+					HomeGoals:  poisson(homeAdvantage*attack[home]*defence[away], rng),
+					AwayGoals:  poisson(attack[away]*defence[home], rng),
+				})
+			}
+		}
+	}
+	return results
+}
+
+func TestCalibrate(t *testing.T) {
+	// seed.sql'deki beş takım; oranlar savunmada büyük olan çok gol yiyen takımdır
+	attack := map[int]float64{1: 1.2, 2: 1.8, 3: 0.6, 4: 0.9, 5: 1.5}
+	defence := map[int]float64{1: 1.0, 2: 0.6, 3: 1.4, 4: 1.2, 5: 0.8}
+
+	tests := []struct {
+		name              string
+		results           []CalibrationResult
+		wantHomeAdvantage float64
+		tolerance         float64
+		wantAttackOrder   []int // saldırısı güçlüden zayıfa
+		wantDefenceOrder  []int // savunması güçlüden zayıfa
+	}{
+		{
+			name: "every match 2-1",
+			results: func() []CalibrationResult {
+				var results []CalibrationResult
+				for home := 1; home <= 5; home++ {
+					for away := 1; away <= 5; away++ {
+						if home != away {
+							results = append(results, CalibrationResult{HomeTeamID: home, AwayTeamID: away, HomeGoals: 2, AwayGoals: 1})
+						}
+					}
+				}
+				return results
+			}(),
+			wantHomeAdvantage: 2,
+			tolerance:         1e-6,
+		},
+		{
+			name:              "synthetic seasons",
+			results:           syntheticResults(attack, defence, 1.5, 60, 11),
+			wantHomeAdvantage: 1.5,
+			tolerance:         0.1,
+			wantAttackOrder:   []int{2, 5, 1, 4, 3},
+			wantDefenceOrder:  []int{2, 5, 1, 4, 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calibration := NewCalibrationService(newTestDB(t))
+			result, err := calibration.Calibrate(1, CalibrationRequest{Results: tt.results, DryRun: true})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if result.Applied {
+				t.Error("dry run was applied")
+			}
+			if result.Matches != len(tt.results) {
+				t.Errorf("fitted %d matches, want %d", result.Matches, len(tt.results))
+			}
+			if math.Abs(result.HomeAdvantage-tt.wantHomeAdvantage) > tt.tolerance {
+				t.Errorf("home advantage = %.4f, want %.4f ± %g", result.HomeAdvantage, tt.wantHomeAdvantage, tt.tolerance)
+			}
+
+			attackOf := make(map[int]int)
+			defenceOf := make(map[int]int)
+			for _, team := range result.Teams {
+				attackOf[team.TeamID] = team.Attack
+				defenceOf[team.TeamID] = team.Defence
+			}
+			if len(attackOf) != 5 {
+				t.Fatalf("got %d teams, want 5", len(attackOf))
+			}
+			if tt.wantAttackOrder == nil {
+				// Tüm takımlar aynı sonuçları aldıysa hepsi aynı güçte olmalı
+				for id := range attackOf {
+					if attackOf[id] != attackOf[1] || defenceOf[id] != defenceOf[1] {
+						t.Errorf("team %d: %d/%d, want %d/%d like team 1", id, attackOf[id], defenceOf[id], attackOf[1], defenceOf[1])
+					}
+				}
+				return
+			}
+			for i := 1; i < len(tt.wantAttackOrder); i++ {
+				stronger, weaker := tt.wantAttackOrder[i-1], tt.wantAttackOrder[i]
+				if attackOf[stronger] <= attackOf[weaker] {
+					t.Errorf("attack of team %d (%d) is not above team %d (%d)", stronger, attackOf[stronger], weaker, attackOf[weaker])
+				}
+			}
+			for i := 1; i < len(tt.wantDefenceOrder); i++ {
+				stronger, weaker := tt.wantDefenceOrder[i-1], tt.wantDefenceOrder[i]
+				if defenceOf[stronger] <= defenceOf[weaker] {
+					t.Errorf("defence of team %d (%d) is not above team %d (%d)", stronger, defenceOf[stronger], weaker, defenceOf[weaker])
+				}
+			}
+		})
+	}
+}
+
+func TestCalibrateRejectsInvalidResults(t *testing.T) {
+	tests := []struct {
+		name    string
+		results []CalibrationResult
+	}{
+		{"unknown team", []CalibrationResult{{HomeTeamID: 1, AwayTeamID: 99, HomeGoals: 1}}},
+		{"same team", []CalibrationResult{{HomeTeamID: 2, AwayTeamID: 2, HomeGoals: 1}}},
+		{"negative goals", []CalibrationResult{{HomeTeamID: 1, AwayTeamID: 2, HomeGoals: -1}}},
+		{"no home goals", []CalibrationResult{{HomeTeamID: 1, AwayTeamID: 2, AwayGoals: 3}}},
+	}
+
+	calibration := NewCalibrationService(newTestDB(t))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := calibration.Calibrate(1, CalibrationRequest{Results: tt.results, DryRun: true})
+			if !errors.Is(err, ErrInvalidCalibration) {
+				t.Errorf("got %v, want %v", err, ErrInvalidCalibration)
+			}
+		})
+	}
+}
+
+func TestCalibrateDoesNotRewriteTeamsMidSeason(t *testing.T) {
+	db := newTestDB(t)
+	if _, err := NewSimulatorService(db).SimulateWeek(1, 1, 1); err != nil {
+		t.Fatal(err)
+	}
+	attack := map[int]float64{1: 1.2, 2: 1.8, 3: 0.6, 4: 0.9, 5: 1.5}
+	defence := map[int]float64{1: 1.0, 2: 0.6, 3: 1.4, 4: 1.2, 5: 0.8}
+	req := CalibrationRequest{Results: syntheticResults(attack, defence, 1.5, 10, 3)}
+
+	calibration := NewCalibrationService(db)
+	if _, err := calibration.Calibrate(1, req); !errors.Is(err, ErrTeamHasMatches) {
+		t.Fatalf("got %v, want %v", err, ErrTeamHasMatches)
+	}
+	team, err := NewTeamService(db).Get(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if team.Attack != team.Strength || team.Defence != team.Strength {
+		t.Errorf("team 1 was changed to %d/%d", team.Attack, team.Defence)
+	}
+
+	req.DryRun = true
+	if _, err := calibration.Calibrate(1, req); err != nil {
+		t.Errorf("dry run: %v", err)
+	}
+	req.DryRun = false
+
+	// Güçler bütün sezonlarda ortaktır; yeni sezon açılsa da eski aktif sezon oynanmış sayılır
+	seasons := NewSeasonService(db)
+	if _, err := seasons.Create("", nil, ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := calibration.Calibrate(2, req); !errors.Is(err, ErrTeamHasMatches) {
+		t.Fatalf("with season 1 still active: got %v, want %v", err, ErrTeamHasMatches)
+	}
+	if _, err := seasons.Archive(1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := calibration.Calibrate(2, req); err != nil {
+		t.Errorf("with season 1 archived: %v", err)
+	}
+}
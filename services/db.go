@@ -0,0 +1,11 @@
+package services
+
+import "database/sql"
+
+// dbtx *sql.DB ve *sql.Tx'in ortak metotlarıdır; böylece aynı sorgu kodu
+// hem doğrudan hem de bir transaction içinde çalışabilir.
+type dbtx interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
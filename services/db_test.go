@@ -0,0 +1,45 @@
+package services
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// newTestDB db/schema.sql ve db/seed.sql ile geçici bir veritabanı kurar ve
+// migration'ları uygular; uygulamanın ilk açılışındaki beş takımlı lig elde edilir
+func newTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	db := newBaseTestDB(t)
+	if err := Migrate(db); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// newBaseTestDB migration'lardan önceki, sadece db/schema.sql ve db/seed.sql ile kurulmuş
+// veritabanını döner; eski sürümlerden yükseltmeyi denemek için
+func newBaseTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "league.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	for _, file := range []string{"../db/schema.sql", "../db/seed.sql"} {
+		script, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := db.Exec(string(script)); err != nil {
+			t.Fatalf("%s: %v", file, err)
+		}
+	}
+	return db
+}
@@ -0,0 +1,175 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"insider-case/models"
+)
+
+// ErrScheduleLocked fikstür, oynanmış maç varken yeniden oluşturulmak istendiğinde döner.
+var ErrScheduleLocked = errors.New("schedule cannot be regenerated after matches have been played")
+
+// bye tek sayıda takım olduğunda eşleştirmeyi tamamlayan sahte takım ID'si
+const bye = 0
+
+type FixtureService struct {
+	DB *sql.DB
+}
+
+func NewFixtureService(db *sql.DB) *FixtureService {
+	return &FixtureService{DB: db}
+}
+
+// EnsureSchedule sezonun hiç maçı yoksa çift devreli fikstürü oluşturup kaydeder.
+func (f *FixtureService) EnsureSchedule(seasonID int) error {
+	return f.inTx(func(tx dbtx) error { return ensureSchedule(tx, seasonID) })
+}
+
+// ensureSchedule EnsureSchedule'ın haftanın simülasyonu ve sezon açılışıyla aynı
+// transaction içinde çalışabilen hali
+func ensureSchedule(q dbtx, seasonID int) error {
+	var count int
+	if err := q.QueryRow("SELECT COUNT(*) FROM matches WHERE season_id = ?", seasonID).Scan(&count); err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	return saveSchedule(q, seasonID)
+}
+
+// RegenerateSchedule sezonun oynanmamış fikstürünü silip yeniden oluşturur.
+// Oynanmış en az bir maç varsa ErrScheduleLocked döner.
+func (f *FixtureService) RegenerateSchedule(seasonID int) error {
+	return f.inTx(func(tx dbtx) error { return regenerateSchedule(tx, seasonID) })
+}
+
+// inTx fn'i tek bir transaction içinde çalıştırır; hata olursa hiçbir değişiklik kalmaz
+func (f *FixtureService) inTx(fn func(tx dbtx) error) error {
+	tx, err := f.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// regenerateSchedule RegenerateSchedule'ın takım ekleme ve silme ile aynı transaction
+// içinde çalışabilen hali
+func regenerateSchedule(q dbtx, seasonID int) error {
+	var played int
+	err := q.QueryRow("SELECT COUNT(*) FROM matches WHERE season_id = ? AND played = 1", seasonID).Scan(&played)
+	if err != nil {
+		return err
+	}
+	if played > 0 {
+		return ErrScheduleLocked
+	}
+
+	if _, err := q.Exec("DELETE FROM matches WHERE season_id = ? AND played = 0", seasonID); err != nil {
+		return err
+	}
+	return saveSchedule(q, seasonID)
+}
+
+func saveSchedule(q dbtx, seasonID int) error {
+	teamIDs, err := teamIDs(q)
+	if err != nil {
+		return err
+	}
+	if len(teamIDs) < 2 {
+		return errors.New("not enough teams to generate a schedule")
+	}
+
+	for _, m := range GenerateDoubleRoundRobin(teamIDs) {
+		_, err := q.Exec(`
+			INSERT INTO matches (season_id, week, home_team_id, away_team_id, home_goals, away_goals, played)
+			VALUES (?, ?, ?, ?, 0, 0, 0)`,
+			seasonID, m.Week, m.HomeTeamID, m.AwayTeamID)
+		if err != nil {
+			return err
+		}
+	}
+	return snapshotInitialRatings(q, seasonID)
+}
+
+// scheduledWeeks teamCount takımlı çift devreli fikstürün hafta sayısı; tek sayıda
+// takımda bay eklendiği için bir takım fazlasıyla aynıdır
+func scheduledWeeks(teamCount int) int {
+	return 2 * (teamCount + teamCount%2 - 1)
+}
+
+func teamIDs(q dbtx) ([]int, error) {
+	rows, err := q.Query("SELECT id FROM teams ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// GenerateDoubleRoundRobin circle (Berger) yöntemiyle çift devreli fikstür üretir.
+// Tek sayıda takımda her hafta bir takım bay geçer; bay eşleşmeleri listeye eklenmez.
+// İkinci devre, ilk devrenin ev sahibi/deplasman yer değiştirmiş tekrarıdır.
+func GenerateDoubleRoundRobin(teamIDs []int) []models.Match {
+	slots := append([]int(nil), teamIDs...)
+	if len(slots)%2 != 0 {
+		// Bay sabit konuma konur, böylece gerçek takımların hepsi döner ve
+		// ev sahibi/deplasman sayıları dengeli kalır
+		slots = append([]int{bye}, slots...)
+	}
+
+	n := len(slots)
+	rounds := n - 1
+
+	var firstHalf []models.Match
+	for round := 0; round < rounds; round++ {
+		for i := 0; i < n/2; i++ {
+			home, away := slots[i], slots[n-1-i]
+
+			// Sabit takım her hafta saha değiştirir, diğer eşleşmeler
+			// sıraya göre dönüşümlü olarak ters çevrilir.
+			if (i == 0 && round%2 == 1) || (i > 0 && i%2 == 1) {
+				home, away = away, home
+			}
+			if home == bye || away == bye {
+				continue
+			}
+
+			firstHalf = append(firstHalf, models.Match{
+				Week:       round + 1,
+				HomeTeamID: home,
+				AwayTeamID: away,
+			})
+		}
+
+		// İlk takım sabit kalır, diğerleri saat yönünde bir adım döner
+		last := slots[n-1]
+		copy(slots[2:], slots[1:n-1])
+		slots[1] = last
+	}
+
+	schedule := make([]models.Match, 0, len(firstHalf)*2)
+	schedule = append(schedule, firstHalf...)
+	for _, m := range firstHalf {
+		schedule = append(schedule, models.Match{
+			Week:       m.Week + rounds,
+			HomeTeamID: m.AwayTeamID,
+			AwayTeamID: m.HomeTeamID,
+		})
+	}
+	return schedule
+}
@@ -0,0 +1,90 @@
+package services
+
+import "testing"
+
+func TestGenerateDoubleRoundRobin(t *testing.T) {
+	tests := []struct {
+		name  string
+		teams []int
+		weeks int
+	}{
+		{"two teams", []int{1, 2}, 2},
+		{"four teams", []int{1, 2, 3, 4}, 6},
+		{"five teams with byes", []int{1, 2, 3, 4, 5}, 10},
+		{"six teams", []int{3, 8, 11, 20, 21, 40}, 10},
+		{"seven teams with byes", []int{1, 2, 3, 4, 5, 6, 7}, 14},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule := GenerateDoubleRoundRobin(tt.teams)
+			n := len(tt.teams)
+
+			if want := n * (n - 1); len(schedule) != want {
+				t.Fatalf("got %d matches, want %d", len(schedule), want)
+			}
+
+			pairs := make(map[[2]int]int)
+			home := make(map[int]int)
+			away := make(map[int]int)
+			playing := make(map[int]map[int]bool)
+			maxWeek := 0
+			for _, m := range schedule {
+				if m.HomeTeamID == m.AwayTeamID {
+					t.Fatalf("week %d: team %d plays itself", m.Week, m.HomeTeamID)
+				}
+				pairs[[2]int{m.HomeTeamID, m.AwayTeamID}]++
+				home[m.HomeTeamID]++
+				away[m.AwayTeamID]++
+
+				if playing[m.Week] == nil {
+					playing[m.Week] = make(map[int]bool)
+				}
+				for _, id := range []int{m.HomeTeamID, m.AwayTeamID} {
+					if playing[m.Week][id] {
+						t.Fatalf("week %d: team %d plays twice", m.Week, id)
+					}
+					playing[m.Week][id] = true
+				}
+				maxWeek = max(maxWeek, m.Week)
+			}
+
+			if maxWeek != tt.weeks || len(playing) != tt.weeks {
+				t.Fatalf("got %d weeks (last week %d), want %d", len(playing), maxWeek, tt.weeks)
+			}
+
+			// Her takım diğer her takımı bir kez evinde, bir kez deplasmanda ağırlar
+			for _, a := range tt.teams {
+				for _, b := range tt.teams {
+					if a != b && pairs[[2]int{a, b}] != 1 {
+						t.Errorf("%d vs %d is scheduled %d times, want 1", a, b, pairs[[2]int{a, b}])
+					}
+				}
+				if home[a] != n-1 || away[a] != n-1 {
+					t.Errorf("team %d: %d home and %d away matches, want %d each", a, home[a], away[a], n-1)
+				}
+			}
+
+			// Tek sayıda takımda her hafta tam bir takım bay geçer, her takım iki kez
+			byes := make(map[int]int)
+			for week, teams := range playing {
+				idle := n - len(teams)
+				if want := n % 2; idle != want {
+					t.Errorf("week %d: %d teams idle, want %d", week, idle, want)
+				}
+				for _, id := range tt.teams {
+					if !teams[id] {
+						byes[id]++
+					}
+				}
+			}
+			if n%2 == 1 {
+				for _, id := range tt.teams {
+					if byes[id] != 2 {
+						t.Errorf("team %d has %d byes, want 2", id, byes[id])
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package services
+
+import "strings"
+
+// formLength tablodaki formda ve form_length verilmediğinde form_guide'da gösterilen son maç sayısı
+const formLength = 5
+
+// Streaks üst üste galibiyet, yenilmezlik ve mağlubiyet serileri
+type Streaks struct {
+	Winning  int `json:"winning"`
+	Unbeaten int `json:"unbeaten"`
+	Losing   int `json:"losing"`
+}
+
+// FormGuide takımın son maçları ve serileri
+type FormGuide struct {
+	Form    string  `json:"form"`    // Son N maç eskiden yeniye, örn. "WWDLW"
+	Current Streaks `json:"current"` // Son maçta devam eden seriler
+	Longest Streaks `json:"longest"` // Sezonun en uzun serileri
+}
+
+// AddFormGuides tablodaki her satıra son n maçın formunu ve serileri ekler; n 0 ise son 5 maç.
+// Seriler hesaplanan tablonun kapsadığı maçlardan (hafta ve görünüm filtresiyle) çıkarılır.
+func AddFormGuides(standings []TeamStats, n int) {
+	if n <= 0 {
+		n = formLength
+	}
+	for i := range standings {
+		standings[i].FormGuide = newFormGuide(standings[i].results, n)
+	}
+}
+
+func newFormGuide(results []string, n int) *FormGuide {
+	last := results
+	if len(last) > n {
+		last = last[len(last)-n:]
+	}
+	guide := &FormGuide{Form: strings.Join(last, "")}
+
+	for _, result := range results {
+		if result == FormWin {
+			guide.Current.Winning++
+		} else {
+			guide.Current.Winning = 0
+		}
+		if result != FormLoss {
+			guide.Current.Unbeaten++
+		} else {
+			guide.Current.Unbeaten = 0
+		}
+		if result == FormLoss {
+			guide.Current.Losing++
+		} else {
+			guide.Current.Losing = 0
+		}
+
+		guide.Longest.Winning = max(guide.Longest.Winning, guide.Current.Winning)
+		guide.Longest.Unbeaten = max(guide.Longest.Unbeaten, guide.Current.Unbeaten)
+		guide.Longest.Losing = max(guide.Longest.Losing, guide.Current.Losing)
+	}
+	return guide
+}
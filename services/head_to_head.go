@@ -0,0 +1,106 @@
+package services
+
+import "fmt"
+
+// HeadToHeadRecord iki takımın karşılaşmalarının özeti, takımın (opponent değil) açısından
+type HeadToHeadRecord struct {
+	Played       int `json:"played"`
+	Won          int `json:"won"`
+	Drawn        int `json:"drawn"`
+	Lost         int `json:"lost"`
+	GoalsFor     int `json:"goals_for"`
+	GoalsAgainst int `json:"goals_against"`
+}
+
+// HeadToHead iki takımın tüm sezonlardaki oynanmış maçları ve özetleri
+type HeadToHead struct {
+	TeamID     int              `json:"team_id"`
+	Team       string           `json:"team"`
+	OpponentID int              `json:"opponent_id"`
+	Opponent   string           `json:"opponent"`
+	Overall    HeadToHeadRecord `json:"overall"`
+	Home       HeadToHeadRecord `json:"home"` // Takımın iç sahada oynadığı maçlar
+	Away       HeadToHeadRecord `json:"away"` // Takımın deplasmanda oynadığı maçlar
+	// En farklı galibiyet ve mağlubiyet; eşit farkta daha çok gollü, sonra daha eski maç
+	BiggestWin  *MatchDetail  `json:"biggest_win"`
+	BiggestLoss *MatchDetail  `json:"biggest_loss"`
+	Meetings    []MatchDetail `json:"meetings"`
+}
+
+// add maçı takımın attığı ve yediği gollerle özete ekler
+func (r *HeadToHeadRecord) add(goalsFor, goalsAgainst int) {
+	r.Played++
+	r.GoalsFor += goalsFor
+	r.GoalsAgainst += goalsAgainst
+	switch {
+	case goalsFor > goalsAgainst:
+		r.Won++
+	case goalsFor < goalsAgainst:
+		r.Lost++
+	default:
+		r.Drawn++
+	}
+}
+
+// HeadToHead iki takımın tüm sezonlarda oynanmış karşılaşmalarını sezon ve hafta sırasıyla döner
+func (t *TeamService) HeadToHead(id, otherID int) (*HeadToHead, error) {
+	if id == otherID {
+		return nil, fmt.Errorf("%w: a team cannot be compared with itself", ErrInvalidTeam)
+	}
+	team, err := t.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	opponent, err := t.Get(otherID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := t.DB.Query(matchDetailQuery+`
+		WHERE m.played = 1
+			AND ((m.home_team_id = ? AND m.away_team_id = ?) OR (m.home_team_id = ? AND m.away_team_id = ?))
+		ORDER BY m.season_id, m.week, m.id`,
+		id, otherID, otherID, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	h2h := &HeadToHead{
+		TeamID:     team.ID,
+		Team:       team.Name,
+		OpponentID: opponent.ID,
+		Opponent:   opponent.Name,
+		Meetings:   []MatchDetail{},
+	}
+	var winMargin, lossMargin, winGoals, lossGoals int
+	for rows.Next() {
+		match, err := scanMatchDetail(rows)
+		if err != nil {
+			return nil, err
+		}
+		h2h.Meetings = append(h2h.Meetings, match)
+
+		goalsFor, goalsAgainst := match.HomeGoals, match.AwayGoals
+		if match.HomeTeamID == id {
+			h2h.Home.add(goalsFor, goalsAgainst)
+		} else {
+			goalsFor, goalsAgainst = goalsAgainst, goalsFor
+			h2h.Away.add(goalsFor, goalsAgainst)
+		}
+		h2h.Overall.add(goalsFor, goalsAgainst)
+
+		margin := goalsFor - goalsAgainst
+		switch {
+		case margin > 0 && (margin > winMargin || margin == winMargin && goalsFor > winGoals):
+			winMargin, winGoals = margin, goalsFor
+			biggest := match
+			h2h.BiggestWin = &biggest
+		case margin < 0 && (-margin > lossMargin || -margin == lossMargin && goalsAgainst > lossGoals):
+			lossMargin, lossGoals = -margin, goalsAgainst
+			biggest := match
+			h2h.BiggestLoss = &biggest
+		}
+	}
+	return h2h, rows.Err()
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrSeasonFinished sezonda oynanacak maç kalmadığında döner
+	ErrSeasonFinished = errors.New("season is finished")
+	// ErrWeekOutOfOrder önceki haftalarda oynanmamış maç varken ileri bir hafta oynatılmak istendiğinde döner
+	ErrWeekOutOfOrder = errors.New("earlier weeks have unplayed fixtures")
+)
+
+// LeagueState sezonun fikstürdeki ilerleyişini özetler.
+// CurrentWeek sıradaki oynanacak haftadır; sezon bittiyse son haftadır.
+type LeagueState struct {
+	SeasonID       int  `json:"season_id"`
+	CurrentWeek    int  `json:"current_week"`
+	TotalWeeks     int  `json:"total_weeks"`
+	WeeksPlayed    int  `json:"weeks_played"`
+	WeeksRemaining int  `json:"weeks_remaining"`
+	Finished       bool `json:"finished"`
+}
+
+// GetLeagueState sezonun güncel haftasını fikstürden hesaplar
+func (s *SimulatorService) GetLeagueState(seasonID int) (*LeagueState, error) {
+	if err := s.fixtures.EnsureSchedule(seasonID); err != nil {
+		return nil, err
+	}
+	return leagueState(s.DB, seasonID)
+}
+
+// leagueState sezonun durumunu q üzerinden hesaplar; fikstürün oluşturulmuş olduğunu varsayar
+func leagueState(q dbtx, seasonID int) (*LeagueState, error) {
+	// Haftalar fikstürde bulunan haftalardan sayılır; aradaki boş haftalar sayılmaz
+	state := &LeagueState{SeasonID: seasonID}
+	var lastWeek int
+	err := q.QueryRow(`
+		SELECT
+			COUNT(DISTINCT week),
+			COUNT(DISTINCT CASE WHEN played = 1 THEN week END),
+			COUNT(DISTINCT CASE WHEN played = 0 THEN week END),
+			COALESCE(MIN(CASE WHEN played = 0 THEN week END), 0),
+			COALESCE(MAX(week), 0)
+		FROM matches
+		WHERE season_id = ?`, seasonID).
+		Scan(&state.TotalWeeks, &state.WeeksPlayed, &state.WeeksRemaining, &state.CurrentWeek, &lastWeek)
+	if err != nil {
+		return nil, err
+	}
+
+	state.Finished = state.WeeksRemaining == 0
+	if state.Finished {
+		state.CurrentWeek = lastWeek
+	}
+	return state, nil
+}
+
+// SimulateNextWeek sezonun sıradaki haftasını oynatır
+func (s *SimulatorService) SimulateNextWeek(seasonID int, seed int64) (*WeekResult, error) {
+	state, err := s.GetLeagueState(seasonID)
+	if err != nil {
+		return nil, err
+	}
+	if state.Finished {
+		return nil, ErrSeasonFinished
+	}
+
+	return s.SimulateWeek(seasonID, state.CurrentWeek, seed)
+}
+
+// checkWeekOrder haftanın sıradaki hafta olup olmadığını q üzerinden kontrol eder
+func checkWeekOrder(q dbtx, seasonID, week int) error {
+	state, err := leagueState(q, seasonID)
+	if err != nil {
+		return err
+	}
+	if !state.Finished && week > state.CurrentWeek {
+		return fmt.Errorf("cannot play week %d before week %d: %w", week, state.CurrentWeek, ErrWeekOutOfOrder)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLeagueStateCountsScheduledWeeks(t *testing.T) {
+	db := newTestDB(t)
+	simulator := NewSimulatorService(db)
+	if err := simulator.fixtures.EnsureSchedule(1); err != nil {
+		t.Fatal(err)
+	}
+	// Üçüncü haftanın maçları silinerek fikstürde boşluk bırakılır
+	if _, err := db.Exec("DELETE FROM matches WHERE season_id = 1 AND week = 3"); err != nil {
+		t.Fatal(err)
+	}
+
+	steps := []struct {
+		name string
+		play func() error
+		want LeagueState
+	}{
+		{"before kick-off", nil, LeagueState{SeasonID: 1, CurrentWeek: 1, TotalWeeks: 9, WeeksRemaining: 9}},
+		{"after two weeks", func() error {
+			_, err := simulator.SimulateWeek(1, 1, 1)
+			if err == nil {
+				_, err = simulator.SimulateWeek(1, 2, 2)
+			}
+			return err
+		}, LeagueState{SeasonID: 1, CurrentWeek: 4, TotalWeeks: 9, WeeksPlayed: 2, WeeksRemaining: 7}},
+		{"finished", func() error {
+			_, err := simulator.SimulateAllWeeks(1, 3)
+			return err
+		}, LeagueState{SeasonID: 1, CurrentWeek: 10, TotalWeeks: 9, WeeksPlayed: 9, Finished: true}},
+	}
+
+	for _, step := range steps {
+		if step.play != nil {
+			if err := step.play(); err != nil {
+				t.Fatalf("%s: %v", step.name, err)
+			}
+		}
+		state, err := simulator.GetLeagueState(1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(*state, step.want) {
+			t.Errorf("%s: got %+v, want %+v", step.name, *state, step.want)
+		}
+	}
+}
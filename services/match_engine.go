@@ -0,0 +1,214 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"insider-case/models"
+	"math"
+	"math/rand"
+)
+
+// Sezon bazında seçilebilen skor modelleri
+const (
+	EnginePoisson          = "poisson"
+	EngineDixonColes       = "dixon_coles"
+	EngineBivariatePoisson = "bivariate_poisson"
+)
+
+var ErrInvalidEngine = errors.New("invalid match engine")
+
+// poissonTail matris hesaplanırken tavana katlanmadan önce bakılan en yüksek gol sayısı;
+// gol tavanı kapalıyken matris bu değerde kesilir
+const poissonTail = 20
+
+// matchEngines seçilebilen skor modellerinin kaydı; her model sezonun parametreleriyle kurulur
+var matchEngines = map[string]func(p models.SimulationParams) models.MatchEngine{
+	EnginePoisson:          func(p models.SimulationParams) models.MatchEngine { return poissonEngine{p} },
+	EngineDixonColes:       func(p models.SimulationParams) models.MatchEngine { return dixonColesEngine{p} },
+	EngineBivariatePoisson: func(p models.SimulationParams) models.MatchEngine { return bivariatePoissonEngine{p} },
+}
+
+// ValidateEngine model adını doğrular
+func ValidateEngine(name string) error {
+	if _, ok := matchEngines[name]; !ok {
+		return fmt.Errorf("%w: unknown engine %q", ErrInvalidEngine, name)
+	}
+	return nil
+}
+
+// seasonEngine sezonun seçtiği skor modelini sezonun parametreleriyle kurar ve
+// parametreleri de döner, böylece sonuçlar hangi değerlerle üretildiğiyle birlikte raporlanabilir
+func seasonEngine(q dbtx, seasonID int) (models.MatchEngine, models.SimulationParams, error) {
+	var name string
+	if err := q.QueryRow("SELECT engine FROM seasons WHERE id = ?", seasonID).Scan(&name); err != nil {
+		return nil, models.SimulationParams{}, err
+	}
+	params, err := seasonParams(q, seasonID)
+	if err != nil {
+		return nil, params, err
+	}
+	newEngine, ok := matchEngines[name]
+	if !ok {
+		return nil, params, fmt.Errorf("season %d: %w: unknown engine %q", seasonID, ErrInvalidEngine, name)
+	}
+	return newEngine(params), params, nil
+}
+
+// poissonEngine iki takımın gollerini birbirinden bağımsız Poisson ile üretir
+type poissonEngine struct {
+	params models.SimulationParams
+}
+
+func (poissonEngine) Name() string { return EnginePoisson }
+
+func (e poissonEngine) SimulateScore(home, away models.TeamStrength, rng *rand.Rand) (int, int) {
+	return poissonScore(e.params, home, away, rng)
+}
+
+func (e poissonEngine) ScoreProbabilities(home, away models.TeamStrength) [][]float64 {
+	homeLambda, awayLambda := scoringLambdas(e.params, home, away)
+	return independentMatrix(homeLambda, awayLambda, goalLimit(e.params))
+}
+
+// dixonColesEngine bağımsız Poisson'un 0-0, 1-0, 0-1 ve 1-1 olasılıklarını Dixon-Coles
+// katsayısıyla (DixonColesRho) düzeltir; gerçek liglerdeki beraberlik oranına daha yakın sonuç verir
+type dixonColesEngine struct {
+	params models.SimulationParams
+}
+
+func (dixonColesEngine) Name() string { return EngineDixonColes }
+
+func (e dixonColesEngine) SimulateScore(home, away models.TeamStrength, rng *rand.Rand) (int, int) {
+	return sampleScore(e.ScoreProbabilities(home, away), rng)
+}
+
+func (e dixonColesEngine) ScoreProbabilities(home, away models.TeamStrength) [][]float64 {
+	homeLambda, awayLambda := scoringLambdas(e.params, home, away)
+	m := independentMatrix(homeLambda, awayLambda, goalLimit(e.params))
+
+	// Düzeltmeler dört hücrenin toplamını değiştirmez; ancak tau negatif olamayacağı için
+	// büyük |rho| değerlerinde sıfıra kırpılan hücre toplamı bozar, matris yeniden normalize edilir
+	rho := e.params.DixonColesRho
+	m[0][0] *= math.Max(1-homeLambda*awayLambda*rho, 0)
+	m[0][1] *= math.Max(1+homeLambda*rho, 0)
+	m[1][0] *= math.Max(1+awayLambda*rho, 0)
+	m[1][1] *= math.Max(1-rho, 0)
+
+	total := 0.0
+	for _, row := range m {
+		for _, p := range row {
+			total += p
+		}
+	}
+	for _, row := range m {
+		for a := range row {
+			row[a] /= total
+		}
+	}
+	return m
+}
+
+// bivariatePoissonEngine iki takımın golüne ortalaması BivariateCovariance olan ortak bir
+// Poisson bileşeni ekleyerek gollerin ilişkili olmasını sağlar; takımların gol ortalamaları değişmez
+type bivariatePoissonEngine struct {
+	params models.SimulationParams
+}
+
+func (bivariatePoissonEngine) Name() string { return EngineBivariatePoisson }
+
+// lambdas ev sahibi, deplasman ve ortak bileşenin ortalamaları
+func (e bivariatePoissonEngine) lambdas(home, away models.TeamStrength) (float64, float64, float64) {
+	homeLambda, awayLambda := scoringLambdas(e.params, home, away)
+	shared := math.Min(e.params.BivariateCovariance, 0.9*math.Min(homeLambda, awayLambda))
+	return homeLambda - shared, awayLambda - shared, shared
+}
+
+func (e bivariatePoissonEngine) SimulateScore(home, away models.TeamStrength, rng *rand.Rand) (int, int) {
+	homeLambda, awayLambda, shared := e.lambdas(home, away)
+	common := poisson(shared, rng)
+	return capGoals(e.params, poisson(homeLambda, rng)+common), capGoals(e.params, poisson(awayLambda, rng)+common)
+}
+
+func (e bivariatePoissonEngine) ScoreProbabilities(home, away models.TeamStrength) [][]float64 {
+	homeLambda, awayLambda, shared := e.lambdas(home, away)
+	ph, pa, ps := poissonPMF(homeLambda), poissonPMF(awayLambda), poissonPMF(shared)
+
+	limit := goalLimit(e.params)
+	m := newScoreMatrix(limit)
+	for x := 0; x <= poissonTail; x++ {
+		for y := 0; y <= poissonTail; y++ {
+			p := 0.0
+			for k := 0; k <= min(x, y); k++ {
+				p += ph[x-k] * pa[y-k] * ps[k]
+			}
+			m[min(x, limit)][min(y, limit)] += p
+		}
+	}
+	return m
+}
+
+// independentMatrix bağımsız iki Poisson dağılımının 0..limit gollük skor matrisi
+func independentMatrix(homeLambda, awayLambda float64, limit int) [][]float64 {
+	home, away := cappedPoisson(homeLambda, limit), cappedPoisson(awayLambda, limit)
+	m := newScoreMatrix(limit)
+	for h, ph := range home {
+		for a, pa := range away {
+			m[h][a] = ph * pa
+		}
+	}
+	return m
+}
+
+func newScoreMatrix(limit int) [][]float64 {
+	m := make([][]float64, limit+1)
+	for i := range m {
+		m[i] = make([]float64, limit+1)
+	}
+	return m
+}
+
+// cappedPoisson Poisson(lambda) dağılımının 0..limit olasılıkları;
+// limit ve üstü gollerin olasılığı son elemanda toplanır
+func cappedPoisson(lambda float64, limit int) []float64 {
+	pmf := poissonPMF(lambda)
+	probs := make([]float64, limit+1)
+	remaining := 1.0
+	for k := 0; k < limit; k++ {
+		probs[k] = pmf[k]
+		remaining -= pmf[k]
+	}
+	probs[limit] = math.Max(remaining, 0)
+	return probs
+}
+
+// poissonPMF Poisson(lambda) dağılımının 0..poissonTail olasılıkları
+func poissonPMF(lambda float64) []float64 {
+	pmf := make([]float64, poissonTail+1)
+	p := math.Exp(-lambda)
+	for k := range pmf {
+		pmf[k] = p
+		p *= lambda / float64(k+1)
+	}
+	return pmf
+}
+
+// sampleScore skor matrisinden rng ile bir skor çeker
+func sampleScore(m [][]float64, rng *rand.Rand) (int, int) {
+	u := rng.Float64()
+	total := 0.0
+	lastHome, lastAway := 0, 0
+	for h := range m {
+		for a := range m[h] {
+			if m[h][a] <= 0 {
+				continue
+			}
+			total += m[h][a]
+			lastHome, lastAway = h, a
+			if u < total {
+				return h, a
+			}
+		}
+	}
+	// Yuvarlama nedeniyle toplam 1'in altında kalırsa son olası skor
+	return lastHome, lastAway
+}
@@ -0,0 +1,89 @@
+package services
+
+import (
+	"insider-case/models"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestScoreProbabilitiesSumToOne(t *testing.T) {
+	capped := DefaultSimulationParams()
+	uncapped := DefaultSimulationParams()
+	uncapped.CapGoals = false
+	lowCap := DefaultSimulationParams()
+	lowCap.MaxGoals = 2
+	// Dixon-Coles düzeltmesinin bazı hücreleri sıfıra kırptığı uç değerler
+	lowRho := DefaultSimulationParams()
+	lowRho.DixonColesRho = -1
+	highRho := DefaultSimulationParams()
+	highRho.DixonColesRho = 1
+
+	strengths := []struct {
+		name       string
+		home, away models.TeamStrength
+	}{
+		{"even", models.TeamStrength{Attack: 0.85, Defence: 1}, models.TeamStrength{Attack: 0.85, Defence: 1}},
+		{"strong home", models.TeamStrength{Attack: 1, Defence: 0.8}, models.TeamStrength{Attack: 0.5, Defence: 1.3}},
+		{"strong away", models.TeamStrength{Attack: 0.3, Defence: 1.4}, models.TeamStrength{Attack: 1, Defence: 0.7}},
+	}
+	params := []struct {
+		name   string
+		params models.SimulationParams
+	}{
+		{"capped", capped},
+		{"uncapped", uncapped},
+		{"max_goals 2", lowCap},
+		{"rho -1", lowRho},
+		{"rho 1", highRho},
+	}
+
+	for engineName, newEngine := range matchEngines {
+		for _, p := range params {
+			engine := newEngine(p.params)
+			for _, s := range strengths {
+				t.Run(engineName+"/"+p.name+"/"+s.name, func(t *testing.T) {
+					m := engine.ScoreProbabilities(s.home, s.away)
+					if want := goalLimit(p.params) + 1; len(m) != want {
+						t.Fatalf("matrix has %d rows, want %d", len(m), want)
+					}
+
+					total := 0.0
+					for h, row := range m {
+						if len(row) != len(m) {
+							t.Fatalf("row %d has %d cells, want %d", h, len(row), len(m))
+						}
+						for a, prob := range row {
+							if prob < 0 {
+								t.Fatalf("P(%d-%d) = %g is negative", h, a, prob)
+							}
+							total += prob
+						}
+					}
+					if math.Abs(total-1) > 1e-9 {
+						t.Errorf("probabilities sum to %.12f, want 1", total)
+					}
+				})
+			}
+		}
+	}
+}
+
+func TestSimulateScoreSameSeedSameScores(t *testing.T) {
+	home := models.TeamStrength{Attack: 0.9, Defence: 0.95}
+	away := models.TeamStrength{Attack: 0.8, Defence: 1.05}
+
+	for engineName, newEngine := range matchEngines {
+		t.Run(engineName, func(t *testing.T) {
+			engine := newEngine(DefaultSimulationParams())
+			first, second := rand.New(rand.NewSource(7)), rand.New(rand.NewSource(7))
+			for i := 0; i < 50; i++ {
+				h1, a1 := engine.SimulateScore(home, away, first)
+				h2, a2 := engine.SimulateScore(home, away, second)
+				if h1 != h2 || a1 != a2 {
+					t.Fatalf("draw %d: %d-%d and %d-%d from the same seed", i, h1, a1, h2, a2)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"insider-case/models"
+)
+
+// Scoreline bir skor ve olasılığı
+type Scoreline struct {
+	HomeGoals   int     `json:"home_goals"`
+	AwayGoals   int     `json:"away_goals"`
+	Probability float64 `json:"probability"`
+}
+
+// MatchPrediction tek bir maçın skor modelinden analitik olarak hesaplanan olasılıkları.
+// Scores[h][a], ev sahibinin h, deplasmanın a gol atma olasılığıdır; max_goals üstü
+// goller simülasyondaki gibi max_goals'a indirildiğinden son satır/sütun kalan olasılığı toplar.
+// Gol tavanı kapalıysa matris poissonTail golde kesilir.
+type MatchPrediction struct {
+	Engine            string                  `json:"engine"`
+	Params            models.SimulationParams `json:"params"`
+	HomeTeamID        int                     `json:"home_team_id"`
+	HomeTeam          string                  `json:"home_team"`
+	AwayTeamID        int                     `json:"away_team_id"`
+	AwayTeam          string                  `json:"away_team"`
+	HomeStrength      models.TeamStrength     `json:"home_strength"` // Skor modeline verilen çarpanlar, Elo açıksa puandan etkilenir
+	AwayStrength      models.TeamStrength     `json:"away_strength"`
+	HomeWin           float64                 `json:"home_win"`
+	Draw              float64                 `json:"draw"`
+	AwayWin           float64                 `json:"away_win"`
+	HomeExpectedGoals float64                 `json:"home_expected_goals"`
+	AwayExpectedGoals float64                 `json:"away_expected_goals"`
+	MostLikelyScore   Scoreline               `json:"most_likely_score"`
+	Scores            [][]float64             `json:"scores"`
+}
+
+// PredictMatch iki takım arasındaki maçın sonucunu sezonun skor modelinin olasılık
+// matrisinden hesaplar; rastgele örnekleme yapılmaz.
+func (s *SimulatorService) PredictMatch(seasonID, homeID, awayID int) (*MatchPrediction, error) {
+	if homeID == awayID {
+		return nil, fmt.Errorf("%w: home and away teams must differ", ErrInvalidTeam)
+	}
+
+	homeName, err := s.teamName(homeID)
+	if err != nil {
+		return nil, err
+	}
+	awayName, err := s.teamName(awayID)
+	if err != nil {
+		return nil, err
+	}
+	engine, params, err := seasonEngine(s.DB, seasonID)
+	if err != nil {
+		return nil, err
+	}
+	strengths, err := matchStrengths(s.DB, seasonID, 0, params)
+	if err != nil {
+		return nil, err
+	}
+	homeStrength, awayStrength := strengths[homeID], strengths[awayID]
+
+	prediction := &MatchPrediction{
+		Engine:       engine.Name(),
+		Params:       params,
+		HomeTeamID:   homeID,
+		HomeTeam:     homeName,
+		AwayTeamID:   awayID,
+		AwayTeam:     awayName,
+		HomeStrength: homeStrength,
+		AwayStrength: awayStrength,
+		Scores:       engine.ScoreProbabilities(homeStrength, awayStrength),
+	}
+
+	for h, row := range prediction.Scores {
+		for a, p := range row {
+			prediction.HomeExpectedGoals += float64(h) * p
+			prediction.AwayExpectedGoals += float64(a) * p
+
+			switch {
+			case h > a:
+				prediction.HomeWin += p
+			case h < a:
+				prediction.AwayWin += p
+			default:
+				prediction.Draw += p
+			}
+			if p > prediction.MostLikelyScore.Probability {
+				prediction.MostLikelyScore = Scoreline{HomeGoals: h, AwayGoals: a, Probability: p}
+			}
+		}
+	}
+	return prediction, nil
+}
+
+// teamName takımın adını döner, yoksa ErrTeamNotFound
+func (s *SimulatorService) teamName(id int) (string, error) {
+	var name string
+	err := s.DB.QueryRow("SELECT name FROM teams WHERE id = ?", id).Scan(&name)
+	if err == sql.ErrNoRows {
+		return "", ErrTeamNotFound
+	}
+	return name, err
+}
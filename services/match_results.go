@@ -0,0 +1,141 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"insider-case/models"
+)
+
+// maxManualGoals elle girilen skorlarda bir takımın en fazla gol sayısı
+const maxManualGoals = 99
+
+var ErrInvalidMatch = errors.New("invalid match")
+
+// SetResult maçın skorunu elle yazar: gerçek bir sonucu kaydetmek ya da simüle edilmiş
+// bir sonucu düzeltmek için. Oynanmamış fikstür maçı oynanmış olur. Puan tablosu, sıralama
+// geçmişi ve Elo puanları aynı transaction içinde yeniden hesaplanır. Arşivlenmiş
+// sezonların maçları değiştirilemez.
+func (s *SimulatorService) SetResult(id, homeGoals, awayGoals int) (*MatchDetail, error) {
+	match, err := s.matches.GetMatch(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.seasons.RequireActive(match.SeasonID); err != nil {
+		return nil, err
+	}
+	if err := validateScore(homeGoals, awayGoals); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec("UPDATE matches SET home_goals = ?, away_goals = ?, result = ?, played = 1 WHERE id = ?",
+		homeGoals, awayGoals, models.ResultFromScore(homeGoals, awayGoals), id)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.recalculate(tx, match.SeasonID); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return s.matches.GetMatch(id)
+}
+
+// AddMatch sezona fikstür dışı bir maç ekler. homeGoals ve awayGoals nil ise maç oynanmamış
+// olarak eklenir ve haftası simüle edildiğinde oynanır; ikisi de verilirse oynanmış olarak
+// eklenir ve tablo SetResult'taki gibi yeniden hesaplanır.
+// Takımlardan biri o hafta zaten oynuyorsa ErrTeamAlreadyScheduled döner.
+func (s *SimulatorService) AddMatch(seasonID, week, homeID, awayID int, homeGoals, awayGoals *int) (*MatchDetail, error) {
+	if err := s.seasons.RequireActive(seasonID); err != nil {
+		return nil, err
+	}
+	teams, err := teamIDs(s.DB)
+	if err != nil {
+		return nil, err
+	}
+	if weeks := scheduledWeeks(len(teams)); week < 1 || week > weeks {
+		return nil, fmt.Errorf("%w: week must be between 1 and %d", ErrInvalidMatch, weeks)
+	}
+	if homeID == awayID {
+		return nil, fmt.Errorf("%w: home and away teams must differ", ErrInvalidTeam)
+	}
+	for _, id := range []int{homeID, awayID} {
+		if _, err := s.teamName(id); err == ErrTeamNotFound {
+			return nil, fmt.Errorf("%w: team %d does not exist", ErrInvalidTeam, id)
+		} else if err != nil {
+			return nil, err
+		}
+	}
+	played := homeGoals != nil && awayGoals != nil
+	if !played && (homeGoals != nil || awayGoals != nil) {
+		return nil, fmt.Errorf("%w: home_goals and away_goals must be given together", ErrInvalidMatch)
+	}
+	if played {
+		if err := validateScore(*homeGoals, *awayGoals); err != nil {
+			return nil, err
+		}
+	}
+
+	// Eklenen maç sezonun ilk maçı olursa fikstür artık oluşturulmayacağından önce oluşturulur
+	if err := s.fixtures.EnsureSchedule(seasonID); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Fikstür oluşturulduktan sonra eklenen takımların da sezon başı puanı olsun
+	if err := snapshotInitialRatings(tx, seasonID); err != nil {
+		return nil, err
+	}
+
+	var goalsHome, goalsAway int
+	if played {
+		goalsHome, goalsAway = *homeGoals, *awayGoals
+	}
+	id, err := s.matches.CreateMatch(tx, seasonID, homeID, awayID, week, goalsHome, goalsAway, played)
+	if err != nil {
+		return nil, err
+	}
+	if played {
+		if _, err := s.recalculate(tx, seasonID); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return s.matches.GetMatch(id)
+}
+
+// recalculate sezonun sonuçları değiştikten sonra puan tablosunu, sıralama geçmişini ve
+// Elo puan geçmişini q üzerinden yeniden hesaplar ve güncel tabloyu döner
+func (s *SimulatorService) recalculate(q dbtx, seasonID int) ([]TeamStats, error) {
+	standings, err := s.refreshStandings(q, seasonID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.rebuildPositionHistory(q, seasonID); err != nil {
+		return nil, err
+	}
+	if err := rebuildRatings(q, seasonID); err != nil {
+		return nil, err
+	}
+	return standings, nil
+}
+
+func validateScore(homeGoals, awayGoals int) error {
+	if homeGoals < 0 || awayGoals < 0 || homeGoals > maxManualGoals || awayGoals > maxManualGoals {
+		return fmt.Errorf("%w: goals must be between 0 and %d", ErrInvalidMatch, maxManualGoals)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package services
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestAddMatch(t *testing.T) {
+	db := newTestDB(t)
+	simulator := NewSimulatorService(db)
+	// Beş takımlı fikstürde her hafta dört takım oynar; son haftanın maçları ertelenmiş
+	// gibi silinerek o hafta bütün takımlar boşa çıkarılır
+	if err := simulator.fixtures.EnsureSchedule(1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("DELETE FROM matches WHERE season_id = 1 AND week = 10"); err != nil {
+		t.Fatal(err)
+	}
+	two, one := 2, 1
+
+	tests := []struct {
+		name      string
+		week      int
+		home      int
+		away      int
+		homeGoals *int
+		awayGoals *int
+		wantErr   error
+	}{
+		{"week zero", 0, 1, 2, nil, nil, ErrInvalidMatch},
+		{"after the last scheduled week", 11, 1, 2, nil, nil, ErrInvalidMatch},
+		{"far beyond the schedule", 50, 1, 2, &two, &one, ErrInvalidMatch},
+		{"scheduled week", 1, 1, 2, nil, nil, ErrTeamAlreadyScheduled},
+		{"free week", 10, 1, 2, &two, &one, nil},
+		{"same home team", 10, 1, 3, nil, nil, ErrTeamAlreadyScheduled},
+		{"same away team", 10, 4, 2, nil, nil, ErrTeamAlreadyScheduled},
+		{"other teams", 10, 3, 4, nil, nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, err := simulator.AddMatch(1, tt.week, tt.home, tt.away, tt.homeGoals, tt.awayGoals)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && (match.Week != tt.week || match.Played != (tt.homeGoals != nil)) {
+				t.Errorf("got %+v", match)
+			}
+		})
+	}
+
+	var count, lastWeek int
+	err := db.QueryRow("SELECT COUNT(CASE WHEN week = 10 THEN 1 END), MAX(week) FROM matches WHERE season_id = 1").
+		Scan(&count, &lastWeek)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 || lastWeek != 10 {
+		t.Errorf("week 10 has %d matches and the last week is %d, want 2 and 10", count, lastWeek)
+	}
+}
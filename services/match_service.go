@@ -3,96 +3,98 @@ package services
 import (
 	"database/sql"
 	"errors"
+	"fmt"
 	"insider-case/models"
 	"math"
 	"math/rand"
-	"time"
 )
 
-type MatchService struct {
-	DB *sql.DB
-}
+var (
+	ErrMatchNotFound        = errors.New("match not found")
+	ErrTeamAlreadyScheduled = errors.New("team already has a match that week")
+)
 
-// NewMatchService constructor
-func NewMatchService(db *sql.DB) *MatchService {
-	return &MatchService{DB: db}
+// MatchDetail maçın API'de takım isimleriyle birlikte dönen hali.
+// Oynanmamış fikstür maçlarında Played false, goller 0'dır ve Result boştur.
+type MatchDetail struct {
+	ID         int           `json:"id"`
+	SeasonID   int           `json:"season_id"`
+	Week       int           `json:"week"`
+	HomeTeamID int           `json:"home_team_id"`
+	HomeTeam   string        `json:"home_team"`
+	AwayTeamID int           `json:"away_team_id"`
+	AwayTeam   string        `json:"away_team"`
+	HomeGoals  int           `json:"home_goals"`
+	AwayGoals  int           `json:"away_goals"`
+	Result     models.Result `json:"result,omitempty"`
+	Played     bool          `json:"played"`
 }
 
-func (m *MatchService) getTeams() ([]models.Team, error) {
-	rows, err := m.DB.Query("SELECT id, name, strength FROM teams")
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
+const matchDetailQuery = `
+	SELECT m.id, m.season_id, m.week, m.home_team_id, h.name, m.away_team_id, a.name,
+		m.home_goals, m.away_goals, m.result, m.played
+	FROM matches m
+	JOIN teams h ON h.id = m.home_team_id
+	JOIN teams a ON a.id = m.away_team_id`
+
+func scanMatchDetail(row interface{ Scan(...any) error }) (MatchDetail, error) {
+	var d MatchDetail
+	err := row.Scan(&d.ID, &d.SeasonID, &d.Week, &d.HomeTeamID, &d.HomeTeam, &d.AwayTeamID, &d.AwayTeam,
+		&d.HomeGoals, &d.AwayGoals, &d.Result, &d.Played)
+	return d, err
+}
 
-	var teams []models.Team
-	for rows.Next() {
-		var t models.Team
-		err := rows.Scan(&t.ID, &t.Name, &t.Strength)
-		if err != nil {
-			return nil, err
-		}
-		teams = append(teams, t)
-	}
+type MatchService struct {
+	DB       *sql.DB
+	fixtures *FixtureService
+}
 
-	return teams, nil
+// NewMatchService constructor
+func NewMatchService(db *sql.DB) *MatchService {
+	return &MatchService{DB: db, fixtures: NewFixtureService(db)}
 }
 
-func (m *MatchService) GenerateRandomMatchesForWeek(week int) error {
-	teams, err := m.getTeams()
+// CreateMatch sezonun verilen haftasına q üzerinden bir maç ekler ve ID'sini döner.
+// played false ise maç oynanmamış fikstür olarak eklenir ve goller yok sayılır. Takımlardan
+// biri o hafta zaten bir maça yazılıysa ErrTeamAlreadyScheduled döner.
+func (m *MatchService) CreateMatch(q dbtx, seasonID, homeTeamID, awayTeamID, week, homeGoals, awayGoals int, played bool) (int, error) {
+	var clashes int
+	err := q.QueryRow(`
+		SELECT COUNT(*) FROM matches
+		WHERE season_id = ? AND week = ?
+			AND (home_team_id IN (?, ?) OR away_team_id IN (?, ?))`,
+		seasonID, week, homeTeamID, awayTeamID, homeTeamID, awayTeamID).Scan(&clashes)
 	if err != nil {
-		return err
+		return 0, err
 	}
-
-	// Takım sayısı tek ise, bir takım haftayı bay geçebilir
-	if len(teams)%2 != 0 {
-		return errors.New("takım sayısı çift olmalı veya bay takımı eklenmeli")
+	if clashes > 0 {
+		return 0, fmt.Errorf("%w: week %d", ErrTeamAlreadyScheduled, week)
 	}
 
-	// Takımları karıştır
-	rand.Seed(time.Now().UnixNano())
-	rand.Shuffle(len(teams), func(i, j int) { teams[i], teams[j] = teams[j], teams[i] })
-
-	// Maçları oluştur: 0-1, 2-3, 4-5 şeklinde eşleştir
-	for i := 0; i < len(teams); i += 2 {
-		homeTeam := teams[i]
-		awayTeam := teams[i+1]
-
-		// Güçlere göre skorları simüle et
-		homeGoals, awayGoals := m.SimulateMatch(homeTeam.Strength, awayTeam.Strength)
-
-		// Maçı DB'ye ekle
-		err := m.CreateMatch(homeTeam.ID, awayTeam.ID, week, homeGoals, awayGoals)
-		if err != nil {
-			return err
-		}
+	var res sql.Result
+	if played {
+		res, err = q.Exec(`
+			INSERT INTO matches (season_id, home_team_id, away_team_id, week, home_goals, away_goals, result, played)
+			VALUES (?, ?, ?, ?, ?, ?, ?, 1)`,
+			seasonID, homeTeamID, awayTeamID, week, homeGoals, awayGoals, models.ResultFromScore(homeGoals, awayGoals))
+	} else {
+		res, err = q.Exec(`
+			INSERT INTO matches (season_id, home_team_id, away_team_id, week, home_goals, away_goals, played)
+			VALUES (?, ?, ?, ?, 0, 0, 0)`,
+			seasonID, homeTeamID, awayTeamID, week)
 	}
-
-	return nil
-}
-
-// CreateMatch inserts a new match record into DB
-func (m *MatchService) CreateMatch(homeTeamID, awayTeamID, week, homeGoals, awayGoals int) error {
-	result := "Draw"
-	switch {
-	case homeGoals > awayGoals:
-		result = "HomeWin"
-	case homeGoals < awayGoals:
-		result = "AwayWin"
+	if err != nil {
+		return 0, err
 	}
-
-	_, err := m.DB.Exec(`
-		INSERT INTO matches (home_team_id, away_team_id, week, home_goals, away_goals, result)
-		VALUES (?, ?, ?, ?, ?, ?)`,
-		homeTeamID, awayTeamID, week, homeGoals, awayGoals, result)
-	return err
+	id, err := res.LastInsertId()
+	return int(id), err
 }
 
-// GetMatchesByWeek returns matches of a given week
-func (m *MatchService) GetMatchesByWeek(week int) ([]models.Match, error) {
+// GetMatchesByWeek returns matches of a given week in a season
+func (m *MatchService) GetMatchesByWeek(seasonID, week int) ([]models.Match, error) {
 	rows, err := m.DB.Query(`
-		SELECT id, home_team_id, away_team_id, week, home_goals, away_goals
-		FROM matches WHERE week = ?`, week)
+		SELECT id, home_team_id, away_team_id, week, home_goals, away_goals, result, played
+		FROM matches WHERE season_id = ? AND week = ?`, seasonID, week)
 	if err != nil {
 		return nil, err
 	}
@@ -101,7 +103,7 @@ func (m *MatchService) GetMatchesByWeek(week int) ([]models.Match, error) {
 	var matches []models.Match
 	for rows.Next() {
 		var match models.Match
-		err := rows.Scan(&match.ID, &match.HomeTeamID, &match.AwayTeamID, &match.Week, &match.HomeGoals, &match.AwayGoals)
+		err := rows.Scan(&match.ID, &match.HomeTeamID, &match.AwayTeamID, &match.Week, &match.HomeGoals, &match.AwayGoals, &match.Result, &match.Played)
 		if err != nil {
 			return nil, err
 		}
@@ -111,118 +113,52 @@ func (m *MatchService) GetMatchesByWeek(week int) ([]models.Match, error) {
 	return matches, nil
 }
 
-func (s *SimulatorService) PredictMatchOutcome(homeID, awayID int) (float64, float64, float64, error) {
-	var home models.Team
-	var away models.Team
-
-	err := s.DB.QueryRow("SELECT played, won, drawn FROM teams WHERE id = ?", homeID).
-		Scan(&home.Played, &home.Won, &home.Drawn)
-	if err != nil {
-		return 0, 0, 0, err
-	}
-
-	err = s.DB.QueryRow("SELECT played, won, drawn FROM teams WHERE id = ?", awayID).
-		Scan(&away.Played, &away.Won, &away.Drawn)
-	if err != nil {
-		return 0, 0, 0, err
-	}
-
-	homeWinRate := 0.0
-	homeDrawRate := 0.0
-	if home.Played > 0 {
-		homeWinRate = float64(home.Won) / float64(home.Played)
-		homeDrawRate = float64(home.Drawn) / float64(home.Played)
-	}
-
-	awayWinRate := 0.0
-	awayDrawRate := 0.0
-	if away.Played > 0 {
-		awayWinRate = float64(away.Won) / float64(away.Played)
-		awayDrawRate = float64(away.Drawn) / float64(away.Played)
-	}
-
-	homeScore := homeWinRate + (awayWinRate * 0.5)
-	awayScore := awayWinRate + (homeWinRate * 0.5)
-	drawScore := (homeDrawRate + awayDrawRate) / 2
-
-	total := homeScore + awayScore + drawScore
-	if total == 0 {
-		return 0.33, 0.34, 0.33, nil
+// ListMatches sezonun maçlarını hafta sırasıyla, takım isimleriyle döner; week 0 ise tüm haftalar.
+// Sezonun fikstürü henüz oluşturulmadıysa önce oluşturulur.
+func (m *MatchService) ListMatches(seasonID, week int) ([]MatchDetail, error) {
+	if err := m.fixtures.EnsureSchedule(seasonID); err != nil {
+		return nil, err
 	}
 
-	return homeScore / total, drawScore / total, awayScore / total, nil
-}
-
-func (s *SimulatorService) GetChampionshipProbabilities() (map[int]float64, error) {
-	rows, err := s.DB.Query("SELECT id, points FROM teams")
+	rows, err := m.DB.Query(matchDetailQuery+`
+		WHERE m.season_id = ? AND (? = 0 OR m.week = ?)
+		ORDER BY m.week, m.id`, seasonID, week, week)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	totalPoints := 0
-	pointsMap := make(map[int]int)
+	matches := []MatchDetail{}
 	for rows.Next() {
-		var id, points int
-		if err := rows.Scan(&id, &points); err != nil {
+		match, err := scanMatchDetail(rows)
+		if err != nil {
 			return nil, err
 		}
-		pointsMap[id] = points
-		totalPoints += points
+		matches = append(matches, match)
 	}
+	return matches, rows.Err()
+}
 
-	probs := make(map[int]float64)
-	teamCount := len(pointsMap)
-	for id, pts := range pointsMap {
-		if totalPoints > 0 {
-			probs[id] = float64(pts) / float64(totalPoints) // puanın toplam puana oranı
-		} else {
-			probs[id] = 1.0 / float64(teamCount) // eşit olasılık (toplam puan 0 ise)
-		}
+// GetMatch verilen ID'li maçı döner, yoksa ErrMatchNotFound
+func (m *MatchService) GetMatch(id int) (*MatchDetail, error) {
+	match, err := scanMatchDetail(m.DB.QueryRow(matchDetailQuery+" WHERE m.id = ?", id))
+	if err == sql.ErrNoRows {
+		return nil, ErrMatchNotFound
 	}
-
-	return probs, nil
+	if err != nil {
+		return nil, err
+	}
+	return &match, nil
 }
 
-func poisson(lambda float64) int {
+// poisson verilen rastgele kaynakla Poisson(lambda) dağılımından örnek çeker
+func poisson(lambda float64, rng *rand.Rand) int {
 	L := math.Exp(-lambda)
 	k := 0
 	p := 1.0
 	for p > L {
 		k++
-		p *= rand.Float64()
+		p *= rng.Float64()
 	}
 	return k - 1
 }
-
-// SimulateMatch simulates a match result based on team strengths and returns scores
-func (m *MatchService) SimulateMatch(homeStrength, awayStrength int) (int, int) {
-	rand.Seed(time.Now().UnixNano())
-
-	// Gücü normalize et (örnek: strength 0-100 arasıysa)
-	homeFactor := float64(homeStrength) / 100.0
-	awayFactor := float64(awayStrength) / 100.0
-
-	// Ortalama gol sayısı, ev sahibi avantajı ile
-	homeLambda := 1.8 * homeFactor // ev sahibi biraz avantajlı
-	awayLambda := 1.0 * awayFactor
-
-	homeGoals := poisson(homeLambda)
-	awayGoals := poisson(awayLambda)
-
-	// Maks 5 gol sınırı koyabilirsin
-	if homeGoals > 5 {
-		homeGoals = 5
-	}
-	if awayGoals > 5 {
-		awayGoals = 5
-	}
-
-	return homeGoals, awayGoals
-}
-
-// DeleteMatchesByWeek deletes matches for a given week - useful if simülasyon tekrar yapılacaksa
-func (m *MatchService) DeleteMatchesByWeek(week int) error {
-	_, err := m.DB.Exec("DELETE FROM matches WHERE week = ?", week)
-	return err
-}
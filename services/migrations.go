@@ -0,0 +1,290 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"insider-case/models"
+)
+
+// migrations db/schema.sql ile oluşturulan temel şemanın üzerine sırayla
+// uygulanır. Her eleman bir kez çalışır ve schema_migrations tablosuna yazılır;
+// mevcut elemanları değiştirmek yerine listenin sonuna yenisi eklenmelidir.
+var migrations = []string{
+	// 1: fikstür desteği - oynanmamış maçlar matches tablosunda played = 0 ile tutulur
+	`ALTER TABLE matches ADD COLUMN played INTEGER NOT NULL DEFAULT 0;
+	UPDATE matches SET played = 1;`,
+
+	// 2: her simüle edilen haftanın tohumu, sonuçların tekrar üretilebilmesi için
+	`CREATE TABLE simulated_weeks (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		week INTEGER NOT NULL,
+		seed INTEGER NOT NULL,
+		simulated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE UNIQUE INDEX idx_simulated_weeks_week ON simulated_weeks (week);`,
+
+	// 3: sezonlar - mevcut maçlar ve hafta tohumları ilk sezona aktarılır
+	`CREATE TABLE seasons (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL,
+		status TEXT NOT NULL DEFAULT 'active',
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		archived_at TIMESTAMP
+	);
+	INSERT INTO seasons (id, name) VALUES (1, 'Season 1');
+	ALTER TABLE matches ADD COLUMN season_id INTEGER NOT NULL DEFAULT 1;
+	CREATE INDEX idx_matches_season_week ON matches (season_id, week);
+	ALTER TABLE simulated_weeks ADD COLUMN season_id INTEGER NOT NULL DEFAULT 1;
+	DROP INDEX idx_simulated_weeks_week;
+	CREATE UNIQUE INDEX idx_simulated_weeks_season_week ON simulated_weeks (season_id, week);`,
+
+	// 4: sezon bazında averaj kuralları, kura tohumu ve takımların fair-play puanı
+	`ALTER TABLE seasons ADD COLUMN tiebreakers TEXT NOT NULL DEFAULT 'goal_difference,goals_for,lots';
+	ALTER TABLE seasons ADD COLUMN lots_seed INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE teams ADD COLUMN fair_play INTEGER NOT NULL DEFAULT 0;`,
+
+	// 5: her haftanın sonundaki sıralama, sıralama grafiği için
+	`CREATE TABLE standings_history (
+		season_id INTEGER NOT NULL,
+		week INTEGER NOT NULL,
+		team_id INTEGER NOT NULL,
+		position INTEGER NOT NULL,
+		points INTEGER NOT NULL,
+		PRIMARY KEY (season_id, week, team_id)
+	);`,
+
+	// 6: sezonun skor modeli
+	`ALTER TABLE seasons ADD COLUMN engine TEXT NOT NULL DEFAULT 'poisson';`,
+
+	// 7: sezonun yapılandırmadan farklı simülasyon parametreleri, JSON nesnesi olarak
+	`ALTER TABLE seasons ADD COLUMN sim_params TEXT NOT NULL DEFAULT '{}';`,
+
+	// 8: takımların her haftanın sonundaki Elo puanı
+	`CREATE TABLE team_ratings (
+		season_id INTEGER NOT NULL,
+		week INTEGER NOT NULL,
+		team_id INTEGER NOT NULL,
+		rating REAL NOT NULL,
+		PRIMARY KEY (season_id, week, team_id)
+	);`,
+
+	// 9: ayrı saldırı ve savunma güçleri, mevcut güçten başlar. seed.sql gibi sadece
+	// strength veren eklemelerde trigger ikisini de strength ile doldurur.
+	`ALTER TABLE teams ADD COLUMN attack INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE teams ADD COLUMN defence INTEGER NOT NULL DEFAULT 0;
+	UPDATE teams SET attack = strength, defence = strength;
+	CREATE TRIGGER teams_default_attack_defence AFTER INSERT ON teams
+	WHEN NEW.attack = 0 OR NEW.defence = 0
+	BEGIN
+		UPDATE teams SET
+			attack = CASE WHEN attack = 0 THEN strength ELSE attack END,
+			defence = CASE WHEN defence = 0 THEN strength ELSE defence END
+		WHERE id = NEW.id;
+	END;`,
+
+	// 10: maç sonuçları tek biçimde. Simülasyon WIN/LOSE/DRAW, CreateMatch HomeWin/AwayWin/Draw
+	// yazıyordu; sonuçlar skordan yeniden hesaplanır ve tablo izin verilen değerleri kısıtlayan
+	// CHECK ile yeniden kurulur. AUTOINCREMENT sayacı silinmiş maçların ID'leri tekrar
+	// kullanılmasın diye korunur.
+	`CREATE TABLE matches_new (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		season_id INTEGER NOT NULL DEFAULT 1,
+		week INTEGER NOT NULL,
+		home_team_id INTEGER NOT NULL,
+		away_team_id INTEGER NOT NULL,
+		home_goals INTEGER DEFAULT 0,
+		away_goals INTEGER DEFAULT 0,
+		result TEXT CHECK (result IN ('HOME_WIN', 'AWAY_WIN', 'DRAW')),
+		played INTEGER NOT NULL DEFAULT 0,
+		CHECK (played = 0 OR result IS NOT NULL),
+		FOREIGN KEY(home_team_id) REFERENCES teams(id),
+		FOREIGN KEY(away_team_id) REFERENCES teams(id)
+	);
+	INSERT INTO matches_new (id, season_id, week, home_team_id, away_team_id, home_goals, away_goals, result, played)
+	SELECT id, season_id, week, home_team_id, away_team_id, home_goals, away_goals,
+		CASE
+			WHEN played = 0 THEN NULL
+			WHEN home_goals > away_goals THEN 'HOME_WIN'
+			WHEN home_goals < away_goals THEN 'AWAY_WIN'
+			ELSE 'DRAW'
+		END,
+		played
+	FROM matches;
+	UPDATE sqlite_sequence
+	SET seq = MAX(seq, COALESCE((SELECT seq FROM sqlite_sequence WHERE name = 'matches'), 0))
+	WHERE name = 'matches_new';
+	DROP TABLE matches;
+	ALTER TABLE matches_new RENAME TO matches;
+	CREATE INDEX idx_matches_season_week ON matches (season_id, week);`,
+
+	// 11: standings_history ve team_ratings tablolarından önce oynanmış sezonların geçmişi
+	// boştur; iki tablo temizlenir ve 13'ten sonra rebuildHistories ile tüm sezonlar için
+	// yeniden kurulur
+	`DELETE FROM standings_history;
+	DELETE FROM team_ratings;`,
+
+	// 12: fikstürden önceki sürümler her haftayı rastgele eşleştirip maçı doğrudan yazıyordu.
+	// Migration 1 bu maçları oynanmış saydığı için ilk sezonun fikstürü hiç oluşturulmuyor ve
+	// sezon bitmiş görünüyordu; archiveLegacySeason bu maçları arşivlenmiş bir sezonda bırakır
+	// ve fikstürlü yeni bir sezon açar
+	``,
+
+	// 13: fair-play puanları sezona ait; teams tablosundaki tek değer güncel sezona aktarılır.
+	// Sıralamalar fair-play puanına bağlı olabildiğinden geçmişler bundan sonra yeniden kurulur.
+	`CREATE TABLE team_fair_play (
+		season_id INTEGER NOT NULL,
+		team_id INTEGER NOT NULL,
+		points INTEGER NOT NULL,
+		PRIMARY KEY (season_id, team_id)
+	);
+	INSERT INTO team_fair_play (season_id, team_id, points)
+	SELECT s.id, t.id, t.fair_play
+	FROM teams t, (SELECT id FROM seasons WHERE status = 'active' ORDER BY id DESC LIMIT 1) s
+	WHERE t.fair_play > 0;
+	ALTER TABLE teams DROP COLUMN fair_play;`,
+
+	// 14: takımların sezon başı puanları 0. hafta olarak team_ratings tablosunda tutulur.
+	// Önceki sezonların başlangıç gücü saklanmadığından güncel güçten yazılır.
+	`INSERT OR IGNORE INTO team_ratings (season_id, week, team_id, rating)
+	SELECT s.id, 0, t.id, 1000 + 10 * t.strength
+	FROM seasons s, teams t;`,
+}
+
+// migrationSteps SQL ile yapılamayan işler için, numarası verilen migration'ın SQL'inden
+// sonra aynı transaction içinde çalışan Go adımları
+var migrationSteps = map[int]func(db *sql.DB, tx *sql.Tx) error{
+	12: archiveLegacySeason,
+	13: rebuildHistories,
+}
+
+// rebuildHistories her sezonun sıralama ve Elo geçmişini oynanmış maçlarından oluşturur
+func rebuildHistories(db *sql.DB, tx *sql.Tx) error {
+	rows, err := tx.Query("SELECT id FROM seasons ORDER BY id")
+	if err != nil {
+		return err
+	}
+	var seasonIDs []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		seasonIDs = append(seasonIDs, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	simulator := NewSimulatorService(db)
+	for _, id := range seasonIDs {
+		if err := simulator.rebuildPositionHistory(tx, id); err != nil {
+			return fmt.Errorf("season %d: %w", id, err)
+		}
+		if err := rebuildRatings(tx, id); err != nil {
+			return fmt.Errorf("season %d: %w", id, err)
+		}
+	}
+	return nil
+}
+
+// archiveLegacySeason tek sezon varken o sezonun maçlarının hepsi oynanmış ama her takım
+// çifti evinde ve deplasmanda karşılaşmamışsa, yani maçlar fikstürden değil eski rastgele
+// eşleştirmeden geliyorsa, sezonu arşivler. Yerine aynı ayarlarla yeni bir aktif sezon açılır,
+// fikstürü oluşturulur ve teams tablosundaki eski sezonun istatistikleri sıfırlanır.
+func archiveLegacySeason(db *sql.DB, tx *sql.Tx) error {
+	var seasons, matches, unplayed, missingPairs int
+	err := tx.QueryRow(`
+		SELECT
+			(SELECT COUNT(*) FROM seasons),
+			(SELECT COUNT(*) FROM matches WHERE season_id = 1),
+			(SELECT COUNT(*) FROM matches WHERE season_id = 1 AND played = 0),
+			(SELECT COUNT(*) FROM teams h JOIN teams a ON a.id != h.id
+			WHERE NOT EXISTS (
+				SELECT 1 FROM matches m
+				WHERE m.season_id = 1 AND m.home_team_id = h.id AND m.away_team_id = a.id))`,
+	).Scan(&seasons, &matches, &unplayed, &missingPairs)
+	if err != nil {
+		return err
+	}
+	if seasons != 1 || matches == 0 || unplayed > 0 || missingPairs == 0 {
+		return nil
+	}
+
+	res, err := tx.Exec(`
+		UPDATE seasons SET status = ?, archived_at = CURRENT_TIMESTAMP
+		WHERE id = 1 AND status = ?`, models.SeasonArchived, models.SeasonActive)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return err
+	}
+
+	res, err = tx.Exec(`
+		INSERT INTO seasons (name, status, tiebreakers, lots_seed, engine, sim_params)
+		SELECT 'Season 2', ?, tiebreakers, ?, engine, sim_params FROM seasons WHERE id = 1`,
+		models.SeasonActive, NewSeed())
+	if err != nil {
+		return err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	if err := saveSchedule(tx, int(id)); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		UPDATE teams
+		SET position = 0, played = 0, won = 0, drawn = 0, lost = 0, gf = 0, ga = 0, gd = 0, points = 0, fair_play = 0`)
+	return err
+}
+
+// Migrate applies every pending migration, each inside its own transaction.
+func Migrate(db *sql.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`)
+	if err != nil {
+		return err
+	}
+
+	var current int
+	err = db.QueryRow("SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&current)
+	if err != nil {
+		return err
+	}
+
+	for i := current; i < len(migrations); i++ {
+		version := i + 1
+
+		tx, err := db.Begin()
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(migrations[i]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %d failed: %w", version, err)
+		}
+		if step, ok := migrationSteps[version]; ok {
+			if err := step(db, tx); err != nil {
+				tx.Rollback()
+				return fmt.Errorf("migration %d failed: %w", version, err)
+			}
+		}
+		if _, err := tx.Exec("INSERT INTO schema_migrations (version) VALUES (?)", version); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,71 @@
+package services
+
+import (
+	"insider-case/models"
+	"testing"
+)
+
+func TestMigrateArchivesRandomlyPairedSeason(t *testing.T) {
+	tests := []struct {
+		name         string
+		legacyWeeks  int
+		wantArchived bool
+	}{
+		{"fresh install", 0, false},
+		{"two random weeks", 2, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newBaseTestDB(t)
+			// Fikstürden önceki sürüm gibi her hafta iki maç, sonuç eski biçimde
+			for week := 1; week <= tt.legacyWeeks; week++ {
+				_, err := db.Exec(`
+					INSERT INTO matches (week, home_team_id, away_team_id, home_goals, away_goals, result)
+					VALUES (?, 1, 2, 2, 1, 'WIN'), (?, 3, 4, 0, 0, 'DRAW')`, week, week)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := Migrate(db); err != nil {
+				t.Fatal(err)
+			}
+
+			seasons := NewSeasonService(db)
+			first, err := seasons.Get(1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := first.Status == models.SeasonArchived; got != tt.wantArchived {
+				t.Fatalf("season 1 status %q", first.Status)
+			}
+			current, err := seasons.Current()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantArchived && current.ID == 1 {
+				t.Fatal("no new season was opened")
+			}
+
+			var legacy, fixtures int
+			err = db.QueryRow(`
+				SELECT
+					(SELECT COUNT(*) FROM matches WHERE season_id = 1 AND played = 1),
+					(SELECT COUNT(*) FROM matches WHERE season_id = ? AND played = 0)`, current.ID,
+			).Scan(&legacy, &fixtures)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if legacy != 2*tt.legacyWeeks {
+				t.Errorf("season 1 kept %d played matches, want %d", legacy, 2*tt.legacyWeeks)
+			}
+			if tt.wantArchived && fixtures != 20 {
+				t.Errorf("season %d has %d fixtures, want 20", current.ID, fixtures)
+			}
+
+			if _, err := NewSimulatorService(db).SimulateWeek(current.ID, 1, 1); err != nil {
+				t.Errorf("simulate week 1: %v", err)
+			}
+		})
+	}
+}
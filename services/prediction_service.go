@@ -0,0 +1,195 @@
+package services
+
+import (
+	"fmt"
+	"insider-case/models"
+	"math/rand"
+	"sort"
+)
+
+// Haftalık simülasyon öncesi tahminlerde kullanılan tekrar sayısı ve ilk N sınırı
+const (
+	defaultPredictionIterations = 1000
+	defaultPredictionTop        = 2
+)
+
+// ChampionshipPrediction Monte Carlo sezon simülasyonunun özetidir.
+type ChampionshipPrediction struct {
+	Engine           string                  `json:"engine"`
+	Params           models.SimulationParams `json:"params"`
+	Iterations       int                     `json:"iterations"`
+	Top              int                     `json:"top"`
+	Seed             int64                   `json:"seed"`
+	RemainingMatches int                     `json:"remaining_matches"`
+	Teams            []TeamPrediction        `json:"teams"`
+}
+
+// TeamPrediction bir takımın sezon sonu olasılıklarını tutar.
+// Positions[i], takımın sezonu (i+1). sırada bitirme olasılığıdır.
+type TeamPrediction struct {
+	TeamID    int       `json:"team_id"`
+	Name      string    `json:"name"`
+	Points    int       `json:"points"`
+	Title     float64   `json:"title"`
+	TopN      float64   `json:"top_n"`
+	Positions []float64 `json:"positions"`
+}
+
+// PredictChampionship mevcut maç sonuçlarından başlayarak kalan fikstürü
+// sezonun haftalık simülasyonda kullandığı skor modeliyle iterations kez oynatır ve takımların
+// şampiyonluk, ilk top sıra ve bitiş sırası olasılıklarını hesaplar. Her turun tablosu
+// /standings gibi sezonun averaj kurallarıyla sıralanır. Turlar seed ile oluşturulan rastgele
+// kaynaktan oynanır; aynı seed aynı durumda aynı olasılıkları verir.
+func (s *SimulatorService) PredictChampionship(seasonID, iterations, top int, seed int64) (*ChampionshipPrediction, error) {
+	if iterations < 1 {
+		return nil, fmt.Errorf("iterations must be positive")
+	}
+	if err := s.fixtures.EnsureSchedule(seasonID); err != nil {
+		return nil, err
+	}
+	return s.predictChampionship(s.DB, seasonID, iterations, top, seed)
+}
+
+// predictChampionship tahmini q üzerinden hesaplar; haftanın simülasyonu kendi transaction'ı
+// içinde hafta oynanmadan önceki tahmini bununla alır. Hiçbir şey yazmaz.
+func (s *SimulatorService) predictChampionship(q dbtx, seasonID, iterations, top int, seed int64) (*ChampionshipPrediction, error) {
+	teams, err := s.queryTeams(q)
+	if err != nil {
+		return nil, err
+	}
+	if top < 1 || top > len(teams) {
+		top = len(teams)
+	}
+
+	standings, err := s.computeStandings(q, seasonID, 0)
+	if err != nil {
+		return nil, err
+	}
+	current := make(map[int]TeamStats)
+	for _, st := range standings {
+		current[st.Team.ID] = st
+	}
+	rules, lotsSeed, err := seasonTiebreakers(q, seasonID)
+	if err != nil {
+		return nil, err
+	}
+	played, err := playedMatches(q, seasonID, 0)
+	if err != nil {
+		return nil, err
+	}
+	teamIDs := make([]int, len(teams))
+	for i, team := range teams {
+		teamIDs[i] = team.ID
+	}
+	lots := drawLotOrder(teamIDs, lotsSeed)
+
+	remaining, err := remainingFixtures(q, seasonID)
+	if err != nil {
+		return nil, err
+	}
+	engine, params, err := seasonEngine(q, seasonID)
+	if err != nil {
+		return nil, err
+	}
+	// Elo puanları açıksa kalan maçlar güncel puanlarla oynanır, puanlar tur içinde güncellenmez
+	teamStrengths, err := matchStrengths(q, seasonID, 0, params)
+	if err != nil {
+		return nil, err
+	}
+
+	index := make(map[int]int)
+	strengths := make([]models.TeamStrength, len(teams))
+	for i, team := range teams {
+		index[team.ID] = i
+		strengths[i] = teamStrengths[team.ID]
+	}
+
+	rng := rand.New(rand.NewSource(seed))
+	finishes := make([][]int, len(teams))
+	for i := range finishes {
+		finishes[i] = make([]int, len(teams))
+	}
+
+	table := make([]TeamStats, len(teams))
+	for it := 0; it < iterations; it++ {
+		for i, team := range teams {
+			st := current[team.ID]
+			table[i] = TeamStats{Team: st.Team, VenueStats: st.VenueStats}
+		}
+		// İkili averaj kuralları için oynanmış maçlara bu turun maçları eklenir
+		ctx := &tiebreakContext{lots: lots, matches: make([]playedMatch, len(played), len(played)+len(remaining))}
+		copy(ctx.matches, played)
+
+		for _, m := range remaining {
+			home, away := index[m.HomeTeamID], index[m.AwayTeamID]
+			homeGoals, awayGoals := engine.SimulateScore(strengths[home], strengths[away], rng)
+
+			table[home].add(homeGoals, awayGoals)
+			table[away].add(awayGoals, homeGoals)
+			ctx.matches = append(ctx.matches, playedMatch{m.HomeTeamID, m.AwayTeamID, homeGoals, awayGoals})
+		}
+
+		rankStandings(table, rules, ctx)
+		for pos, row := range table {
+			finishes[index[row.Team.ID]][pos]++
+		}
+	}
+
+	prediction := &ChampionshipPrediction{
+		Engine:           engine.Name(),
+		Params:           params,
+		Iterations:       iterations,
+		Top:              top,
+		Seed:             seed,
+		RemainingMatches: len(remaining),
+	}
+	for i, team := range teams {
+		positions := make([]float64, len(teams))
+		topN := 0.0
+		for pos, count := range finishes[i] {
+			positions[pos] = float64(count) / float64(iterations)
+			if pos < top {
+				topN += positions[pos]
+			}
+		}
+
+		prediction.Teams = append(prediction.Teams, TeamPrediction{
+			TeamID:    team.ID,
+			Name:      team.Name,
+			Points:    current[team.ID].Points,
+			Title:     positions[0],
+			TopN:      topN,
+			Positions: positions,
+		})
+	}
+
+	sort.SliceStable(prediction.Teams, func(i, j int) bool {
+		return prediction.Teams[i].Title > prediction.Teams[j].Title
+	})
+
+	return prediction, nil
+}
+
+// remainingFixtures sezonun henüz oynanmamış tüm maçlarını döner
+func remainingFixtures(q dbtx, seasonID int) ([]models.Match, error) {
+	rows, err := q.Query(`
+		SELECT id, week, home_team_id, away_team_id
+		FROM matches
+		WHERE season_id = ? AND played = 0
+		ORDER BY week, id
+	`, seasonID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var fixtures []models.Match
+	for rows.Next() {
+		var m models.Match
+		if err := rows.Scan(&m.ID, &m.Week, &m.HomeTeamID, &m.AwayTeamID); err != nil {
+			return nil, err
+		}
+		fixtures = append(fixtures, m)
+	}
+	return fixtures, rows.Err()
+}
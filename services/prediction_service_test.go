@@ -0,0 +1,81 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPredictChampionshipUsesSeasonTiebreakers(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules []string
+	}{
+		{"default chain", DefaultTiebreakers},
+		{"head to head first", []string{TiebreakHeadToHeadPoints, TiebreakHeadToHeadGoalDiff, TiebreakLots}},
+		{"lots only", []string{TiebreakLots}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t)
+			lotsSeed := int64(3)
+			if _, err := NewSeasonService(db).SetTiebreakers(1, tt.rules, &lotsSeed); err != nil {
+				t.Fatal(err)
+			}
+			// Tüm maçlar berabere biter, puanlar eşit kalır ve sırayı sadece averaj zinciri belirler
+			simulator := NewSimulatorService(db)
+			matches, err := NewMatchService(db).ListMatches(1, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, m := range matches {
+				goals := (m.HomeTeamID*m.AwayTeamID + m.Week) % 4
+				if _, err := simulator.SetResult(m.ID, goals, goals); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			// Kalan maç yokken her tur aynı tabloyu vermeli: /standings sırası
+			prediction, err := simulator.PredictChampionship(1, 20, 1, 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			standings, err := simulator.GetCurrentStandings(1)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			positions := make(map[int][]float64)
+			for _, team := range prediction.Teams {
+				positions[team.TeamID] = team.Positions
+			}
+			for i, st := range standings {
+				if p := positions[st.Team.ID][i]; p != 1 {
+					t.Errorf("team %d is %d. in the standings but finishes there with probability %g", st.Team.ID, i+1, p)
+				}
+			}
+		})
+	}
+}
+
+func TestPredictionsAreReproducible(t *testing.T) {
+	var weeks [2]*WeekResult
+	var predictions [2]*ChampionshipPrediction
+	for run := range weeks {
+		simulator := NewSimulatorService(newTestDB(t))
+		var err error
+		if weeks[run], err = simulator.SimulateWeek(1, 1, 9); err != nil {
+			t.Fatal(err)
+		}
+		if predictions[run], err = simulator.PredictChampionship(1, 200, 2, 9); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if !reflect.DeepEqual(weeks[0].Predictions, weeks[1].Predictions) {
+		t.Errorf("week 1 with seed 9 gave different predictions:\n%+v\n%+v", weeks[0].Predictions, weeks[1].Predictions)
+	}
+	if !reflect.DeepEqual(predictions[0], predictions[1]) {
+		t.Errorf("seed 9 gave different predictions:\n%+v\n%+v", predictions[0], predictions[1])
+	}
+}
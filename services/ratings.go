@@ -0,0 +1,289 @@
+package services
+
+import (
+	"database/sql"
+	"insider-case/models"
+	"math"
+)
+
+// Elo puanı ile güç arasındaki dönüşüm: puan = eloBase + eloPerStrength * güç
+const (
+	eloBase        = 1000.0
+	eloPerStrength = 10.0
+)
+
+// WeekRating takımın bir hafta sonundaki Elo puanı ve o haftaki değişimi
+type WeekRating struct {
+	Week   int     `json:"week"`
+	Rating float64 `json:"rating"`
+	Change float64 `json:"change"`
+}
+
+// RatingHistory takımın sezon içindeki Elo geçmişi. Strength takımın sabit gücü,
+// EffectiveStrength güncel puanın güç karşılığıdır; sezon ratings parametresi açıksa
+// skor modeli bu gücü kullanır.
+type RatingHistory struct {
+	SeasonID          int          `json:"season_id"`
+	TeamID            int          `json:"team_id"`
+	Team              string       `json:"team"`
+	Strength          int          `json:"strength"`
+	InitialRating     float64      `json:"initial_rating"`
+	Rating            float64      `json:"rating"`
+	EffectiveStrength int          `json:"effective_strength"`
+	Ratings           []WeekRating `json:"ratings"`
+}
+
+// ratingWeek bir haftanın sonundaki tüm takımların puanları
+type ratingWeek struct {
+	week    int
+	ratings map[int]float64
+}
+
+// initialRating takımın sezon başındaki puanı, gücünden türetilir
+func initialRating(strength int) float64 {
+	return eloBase + eloPerStrength*float64(strength)
+}
+
+// ratingStrength puanın skor modelindeki güç karşılığı, MinStrength..MaxStrength aralığında
+func ratingStrength(rating float64) int {
+	return clampStrength(int(math.Round((rating - eloBase) / eloPerStrength)))
+}
+
+// goalDiffMultiplier farklı galibiyetlerin puanı daha çok değiştirmesi için çarpan
+func goalDiffMultiplier(goalDiff int) float64 {
+	if goalDiff < 0 {
+		goalDiff = -goalDiff
+	}
+	switch {
+	case goalDiff <= 1:
+		return 1
+	case goalDiff == 2:
+		return 1.5
+	default:
+		return (11 + float64(goalDiff)) / 8
+	}
+}
+
+// eloChange maçtan sonra ev sahibinin puanına eklenen değer; deplasman aynı miktarı kaybeder
+func eloChange(p models.SimulationParams, home, away float64, homeGoals, awayGoals int) float64 {
+	expected := 1 / (1 + math.Pow(10, (away-home-p.EloHomeAdvantage)/400))
+
+	actual := 0.5
+	switch {
+	case homeGoals > awayGoals:
+		actual = 1
+	case homeGoals < awayGoals:
+		actual = 0
+	}
+	return p.EloK * goalDiffMultiplier(homeGoals-awayGoals) * (actual - expected)
+}
+
+// snapshotInitialRatings takımların sezon başındaki puanlarını güçlerinden hesaplayıp
+// team_ratings tablosuna 0. hafta olarak yazar. Puanı zaten yazılmış takımlara dokunulmaz;
+// böylece takımın gücü sonradan değişse de sezonun Elo geçmişi değişmez.
+func snapshotInitialRatings(q dbtx, seasonID int) error {
+	_, err := q.Exec(`
+		INSERT OR IGNORE INTO team_ratings (season_id, week, team_id, rating)
+		SELECT ?, 0, id, ? + ? * strength FROM teams`,
+		seasonID, eloBase, eloPerStrength)
+	return err
+}
+
+// setInitialRating takımın sezon başındaki puanını yeni gücüne göre yeniden yazar;
+// sadece takım o sezon henüz oynamamışken çağrılır
+func setInitialRating(q dbtx, seasonID, teamID, strength int) error {
+	_, err := q.Exec(`
+		INSERT INTO team_ratings (season_id, week, team_id, rating) VALUES (?, 0, ?, ?)
+		ON CONFLICT (season_id, week, team_id) DO UPDATE SET rating = excluded.rating`,
+		seasonID, teamID, initialRating(strength))
+	return err
+}
+
+// initialRatings takımların sezon başındaki puanları. 0. hafta satırı olmayan takımların
+// puanı güncel güçlerinden türetilir.
+func initialRatings(q dbtx, seasonID int) (map[int]float64, error) {
+	rows, err := q.Query(`
+		SELECT t.id, t.strength, r.rating
+		FROM teams t
+		LEFT JOIN team_ratings r ON r.team_id = t.id AND r.season_id = ? AND r.week = 0`, seasonID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	initial := make(map[int]float64)
+	for rows.Next() {
+		var id, strength int
+		var rating sql.NullFloat64
+		if err := rows.Scan(&id, &strength, &rating); err != nil {
+			return nil, err
+		}
+		initial[id] = initialRating(strength)
+		if rating.Valid {
+			initial[id] = rating.Float64
+		}
+	}
+	return initial, rows.Err()
+}
+
+// computeRatings sezonun oynanmış maçlarını hafta sırasıyla oynatarak takımların
+// başlangıç puanlarını ve her oynanmış haftanın sonundaki puanlarını hesaplar
+func computeRatings(q dbtx, seasonID int) (map[int]float64, []ratingWeek, error) {
+	params, err := seasonParams(q, seasonID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	initial, err := initialRatings(q, seasonID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, err := q.Query(`
+		SELECT week, home_team_id, away_team_id, home_goals, away_goals
+		FROM matches
+		WHERE season_id = ? AND played = 1
+		ORDER BY week, id
+	`, seasonID)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	current := make(map[int]float64, len(initial))
+	for id, rating := range initial {
+		current[id] = rating
+	}
+	var weeks []ratingWeek
+	snapshot := func(week int) {
+		ratings := make(map[int]float64, len(current))
+		for id, rating := range current {
+			ratings[id] = rating
+		}
+		weeks = append(weeks, ratingWeek{week: week, ratings: ratings})
+	}
+
+	week := 0
+	for rows.Next() {
+		var matchWeek, homeID, awayID, homeGoals, awayGoals int
+		if err := rows.Scan(&matchWeek, &homeID, &awayID, &homeGoals, &awayGoals); err != nil {
+			return nil, nil, err
+		}
+		if week != 0 && matchWeek != week {
+			snapshot(week)
+		}
+		week = matchWeek
+
+		home, homeOK := current[homeID]
+		away, awayOK := current[awayID]
+		if !homeOK || !awayOK {
+			continue
+		}
+		change := eloChange(params, home, away, homeGoals, awayGoals)
+		current[homeID] = home + change
+		current[awayID] = away - change
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+	if week != 0 {
+		snapshot(week)
+	}
+	return initial, weeks, nil
+}
+
+// rebuildRatings sezonun Elo geçmişini oynanmış maçlardan baştan hesaplar; tekrar oynatılan
+// ya da düzeltilen bir maç sonraki haftaların puanlarını da değiştirdiğinden her hafta yeniden
+// yazılır. 0. haftadaki başlangıç puanları korunur.
+func rebuildRatings(q dbtx, seasonID int) error {
+	if _, err := q.Exec("DELETE FROM team_ratings WHERE season_id = ? AND week > 0", seasonID); err != nil {
+		return err
+	}
+
+	_, weeks, err := computeRatings(q, seasonID)
+	if err != nil {
+		return err
+	}
+	for _, w := range weeks {
+		for teamID, rating := range w.ratings {
+			_, err := q.Exec("INSERT INTO team_ratings (season_id, week, team_id, rating) VALUES (?, ?, ?, ?)",
+				seasonID, w.week, teamID, rating)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ratingsBefore takımların verilen haftadan önce oynanmış maçlardan hesaplanan puanları;
+// week 0 ise oynanmış tüm maçlardan hesaplanan güncel puanlar
+func ratingsBefore(q dbtx, seasonID, week int) (map[int]float64, error) {
+	initial, weeks, err := computeRatings(q, seasonID)
+	if err != nil {
+		return nil, err
+	}
+	ratings := initial
+	for _, w := range weeks {
+		if week != 0 && w.week >= week {
+			break
+		}
+		ratings = w.ratings
+	}
+	return ratings, nil
+}
+
+// RatingHistory takımın sezondaki Elo geçmişini team_ratings tablosundan döner; başlangıç
+// puanı sezon açılırken yazılan 0. hafta satırıdır
+func (t *TeamService) RatingHistory(id, seasonID int) (*RatingHistory, error) {
+	team, err := t.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	history := &RatingHistory{
+		SeasonID: seasonID,
+		TeamID:   team.ID,
+		Team:     team.Name,
+		Strength: team.Strength,
+		Ratings:  []WeekRating{},
+	}
+
+	initial, err := initialRatings(t.DB, seasonID)
+	if err != nil {
+		return nil, err
+	}
+	history.Rating = initial[id]
+	history.InitialRating = roundRating(history.Rating)
+
+	rows, err := t.DB.Query("SELECT week, rating FROM team_ratings WHERE season_id = ? AND team_id = ? AND week > 0 ORDER BY week",
+		seasonID, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var w WeekRating
+		var rating float64
+		if err := rows.Scan(&w.Week, &rating); err != nil {
+			return nil, err
+		}
+		w.Rating = roundRating(rating)
+		w.Change = roundRating(rating - history.Rating)
+		history.Rating = rating
+		history.Ratings = append(history.Ratings, w)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	history.EffectiveStrength = ratingStrength(history.Rating)
+	history.Rating = roundRating(history.Rating)
+	return history, nil
+}
+
+// roundRating puanı gösterim için bir ondalığa yuvarlar
+func roundRating(rating float64) float64 {
+	return math.Round(rating*10) / 10
+}
@@ -0,0 +1,55 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRatingHistoryKeepsSeasonStartingRating(t *testing.T) {
+	db := newTestDB(t)
+	if _, err := NewSimulatorService(db).SimulateWeek(1, 1, 1); err != nil {
+		t.Fatal(err)
+	}
+	teams := NewTeamService(db)
+	before, err := teams.RatingHistory(1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	seasons := NewSeasonService(db)
+	if _, err := seasons.Archive(1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := seasons.Create("", nil, ""); err != nil {
+		t.Fatal(err)
+	}
+	team, err := teams.Get(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	strength := team.Strength - 20
+	if _, err := teams.Update(1, team.Name, strength, team.Attack, team.Defence); err != nil {
+		t.Fatal(err)
+	}
+	// Geçmiş yeniden kurulduğunda da sezon başındaki puandan başlanmalı
+	if err := rebuildRatings(db, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	after, err := teams.RatingHistory(1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after.InitialRating != before.InitialRating || !reflect.DeepEqual(after.Ratings, before.Ratings) {
+		t.Errorf("archived season history changed with the team's strength: got %v from %v, want %v from %v",
+			after.Ratings, after.InitialRating, before.Ratings, before.InitialRating)
+	}
+
+	current, err := teams.RatingHistory(1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := initialRating(strength); current.InitialRating != want {
+		t.Errorf("new season starts at %v, want %v", current.InitialRating, want)
+	}
+}
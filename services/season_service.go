@@ -0,0 +1,321 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"insider-case/models"
+	"strings"
+)
+
+var (
+	ErrSeasonNotFound = errors.New("season not found")
+	ErrSeasonArchived = errors.New("season is archived")
+	ErrNoActiveSeason = errors.New("no active season")
+)
+
+type SeasonService struct {
+	DB *sql.DB
+}
+
+func NewSeasonService(db *sql.DB) *SeasonService {
+	return &SeasonService{DB: db}
+}
+
+const seasonColumns = "id, name, status, created_at, archived_at, tiebreakers, lots_seed, engine, sim_params"
+
+func scanSeason(row interface{ Scan(...any) error }) (models.Season, error) {
+	var season models.Season
+	var tiebreakers, overrides string
+	err := row.Scan(&season.ID, &season.Name, &season.Status, &season.CreatedAt, &season.ArchivedAt,
+		&tiebreakers, &season.LotsSeed, &season.Engine, &overrides)
+	if err != nil {
+		return season, err
+	}
+	season.Tiebreakers = parseTiebreakers(tiebreakers)
+	season.ParamOverrides = json.RawMessage(overrides)
+	season.Params, err = effectiveParams(overrides)
+	return season, err
+}
+
+// Create yeni bir sezon açar ve fikstürünü oluşturur. İsim boşsa "Season N" verilir,
+// tiebreakers nil ise DefaultTiebreakers, engine boşsa Poisson modeli kullanılır.
+// Kura tohumu rastgele seçilip saklanır.
+func (s *SeasonService) Create(name string, tiebreakers []string, engine string) (*models.Season, error) {
+	if tiebreakers == nil {
+		tiebreakers = DefaultTiebreakers
+	}
+	tiebreakers, err := ValidateTiebreakers(tiebreakers)
+	if err != nil {
+		return nil, err
+	}
+	if engine == "" {
+		engine = EnginePoisson
+	}
+	if err := ValidateEngine(engine); err != nil {
+		return nil, err
+	}
+
+	// Sezon fikstürüyle birlikte açılır; fikstür oluşturulamazsa sezon da kaydedilmez
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		var count int
+		if err := tx.QueryRow("SELECT COUNT(*) FROM seasons").Scan(&count); err != nil {
+			return nil, err
+		}
+		name = fmt.Sprintf("Season %d", count+1)
+	}
+
+	res, err := tx.Exec("INSERT INTO seasons (name, status, tiebreakers, lots_seed, engine) VALUES (?, ?, ?, ?, ?)",
+		name, models.SeasonActive, strings.Join(tiebreakers, tiebreakerSeparator), NewSeed(), engine)
+	if err != nil {
+		return nil, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ensureSchedule(tx, int(id)); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return s.Get(int(id))
+}
+
+// List tüm sezonları oluşturulma sırasıyla döner
+func (s *SeasonService) List() ([]models.Season, error) {
+	rows, err := s.DB.Query("SELECT " + seasonColumns + " FROM seasons ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	seasons := []models.Season{}
+	for rows.Next() {
+		season, err := scanSeason(rows)
+		if err != nil {
+			return nil, err
+		}
+		seasons = append(seasons, season)
+	}
+	return seasons, rows.Err()
+}
+
+// Get verilen ID'li sezonu döner, yoksa ErrSeasonNotFound
+func (s *SeasonService) Get(id int) (*models.Season, error) {
+	season, err := scanSeason(s.DB.QueryRow("SELECT "+seasonColumns+" FROM seasons WHERE id = ?", id))
+	if err == sql.ErrNoRows {
+		return nil, ErrSeasonNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &season, nil
+}
+
+// Current en son açılmış aktif sezonu döner. Sezon belirtilmeyen istekler bu sezona gider.
+func (s *SeasonService) Current() (*models.Season, error) {
+	id, err := currentSeason(s.DB)
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(id)
+}
+
+// currentSeason güncel sezonun ID'sini q üzerinden okur; aktif sezon yoksa ErrNoActiveSeason döner
+func currentSeason(q dbtx) (int, error) {
+	var id int
+	err := q.QueryRow("SELECT id FROM seasons WHERE status = ? ORDER BY id DESC LIMIT 1", models.SeasonActive).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, ErrNoActiveSeason
+	}
+	return id, err
+}
+
+// Archive sezonu arşivler; arşivlenen sezon saklanır ama artık simüle edilemez
+func (s *SeasonService) Archive(id int) (*models.Season, error) {
+	season, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if season.Status == models.SeasonArchived {
+		return nil, ErrSeasonArchived
+	}
+
+	_, err = s.DB.Exec("UPDATE seasons SET status = ?, archived_at = CURRENT_TIMESTAMP WHERE id = ?", models.SeasonArchived, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(id)
+}
+
+// SetTiebreakers sezonun averaj kural zincirini değiştirir; lotsSeed nil değilse kura
+// tohumu da güncellenir. Arşivlenmiş sezonların tablosu değiştirilemez.
+func (s *SeasonService) SetTiebreakers(id int, tiebreakers []string, lotsSeed *int64) (*models.Season, error) {
+	if err := s.RequireActive(id); err != nil {
+		return nil, err
+	}
+	tiebreakers, err := ValidateTiebreakers(tiebreakers)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.DB.Exec("UPDATE seasons SET tiebreakers = ?, lots_seed = COALESCE(?, lots_seed) WHERE id = ?",
+		strings.Join(tiebreakers, tiebreakerSeparator), lotsSeed, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(id)
+}
+
+// SetEngine sezonun skor modelini değiştirir; sadece bundan sonra oynanacak
+// maçları etkiler. Arşivlenmiş sezonlar değiştirilemez.
+func (s *SeasonService) SetEngine(id int, engine string) (*models.Season, error) {
+	if err := s.RequireActive(id); err != nil {
+		return nil, err
+	}
+	if err := ValidateEngine(engine); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.DB.Exec("UPDATE seasons SET engine = ? WHERE id = ?", engine, id); err != nil {
+		return nil, err
+	}
+	return s.Get(id)
+}
+
+// SetParams sezonun simülasyon parametrelerini değiştirir. overrides bir JSON nesnesidir ve
+// sezonun önceki değişikliklerinin yerine geçer; verilmeyen alanlar yapılandırmadaki değerleri
+// kullanır, {} sezonu tamamen yapılandırmaya döndürür. Oynanmış maçların skorları değişmez,
+// sadece Elo puan geçmişi yeni katsayılarla yeniden hesaplanır.
+func (s *SeasonService) SetParams(id int, overrides json.RawMessage) (*models.Season, error) {
+	if err := s.RequireActive(id); err != nil {
+		return nil, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(overrides, &fields); err != nil {
+		return nil, fmt.Errorf("%w: body must be a JSON object", ErrInvalidParams)
+	}
+	if fields == nil {
+		fields = map[string]json.RawMessage{}
+	}
+	params := configuredParams
+	if err := decodeParams(overrides, &params); err != nil {
+		return nil, err
+	}
+	if err := ValidateSimulationParams(params); err != nil {
+		return nil, err
+	}
+
+	stored, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("UPDATE seasons SET sim_params = ? WHERE id = ?", string(stored), id); err != nil {
+		return nil, err
+	}
+	// Elo katsayıları değişmiş olabilir, puan geçmişi yeni değerlerle yeniden hesaplanır
+	if err := rebuildRatings(tx, id); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return s.Get(id)
+}
+
+// Reset sezonun tüm maçlarını, hafta tohumlarını ve fair-play puanlarını silip fikstürü aynı
+// transaction içinde baştan oluşturur. teams tablosundaki istatistikler güncel sezona aittir;
+// sadece güncel sezon sıfırlanırken temizlenir, eski bir aktif sezonun sıfırlanması onlara dokunmaz.
+func (s *SeasonService) Reset(id int) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := requireActive(tx, id); err != nil {
+		return err
+	}
+	current, err := currentSeason(tx)
+	if err != nil && err != ErrNoActiveSeason {
+		return err
+	}
+
+	if _, err := tx.Exec("DELETE FROM matches WHERE season_id = ?", id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM simulated_weeks WHERE season_id = ?", id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM standings_history WHERE season_id = ?", id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM team_ratings WHERE season_id = ?", id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM team_fair_play WHERE season_id = ?", id); err != nil {
+		return err
+	}
+
+	if current == id {
+		_, err = tx.Exec(`
+			UPDATE teams
+			SET
+				position = 0,
+				played = 0,
+				won = 0,
+				drawn = 0,
+				lost = 0,
+				gf = 0,
+				ga = 0,
+				gd = 0,
+				points = 0;
+		`)
+		if err != nil {
+			return err
+		}
+	}
+	if err := saveSchedule(tx, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// RequireActive sezon yoksa ErrSeasonNotFound, arşivlenmişse ErrSeasonArchived döner
+func (s *SeasonService) RequireActive(id int) error {
+	return requireActive(s.DB, id)
+}
+
+// requireActive RequireActive'in transaction içinde çalışabilen hali
+func requireActive(q dbtx, id int) error {
+	var status string
+	err := q.QueryRow("SELECT status FROM seasons WHERE id = ?", id).Scan(&status)
+	if err == sql.ErrNoRows {
+		return ErrSeasonNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if status != models.SeasonActive {
+		return ErrSeasonArchived
+	}
+	return nil
+}
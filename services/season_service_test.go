@@ -0,0 +1,40 @@
+package services
+
+import "testing"
+
+// seasonCounts sezon sayısını, season 1'in maç sayısını ve oynanmış maç sayısını döner
+func seasonCounts(t *testing.T, s *SeasonService) (seasons, matches, played int) {
+	t.Helper()
+	err := s.DB.QueryRow(`
+		SELECT
+			(SELECT COUNT(*) FROM seasons),
+			(SELECT COUNT(*) FROM matches WHERE season_id = 1),
+			(SELECT COUNT(*) FROM matches WHERE season_id = 1 AND played = 1)`).Scan(&seasons, &matches, &played)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return seasons, matches, played
+}
+
+func TestSeasonScheduleFailureLeavesNothingBehind(t *testing.T) {
+	db := newTestDB(t)
+	if _, err := NewSimulatorService(db).SimulateWeek(1, 1, 1); err != nil {
+		t.Fatal(err)
+	}
+	// Takım kalmayınca fikstür oluşturulamaz
+	if _, err := db.Exec("DELETE FROM teams"); err != nil {
+		t.Fatal(err)
+	}
+	seasons := NewSeasonService(db)
+
+	if _, err := seasons.Create("", nil, ""); err == nil {
+		t.Error("creating a season without teams succeeded")
+	}
+	if err := seasons.Reset(1); err == nil {
+		t.Error("resetting a season without teams succeeded")
+	}
+
+	if count, matches, played := seasonCounts(t, seasons); count != 1 || matches != 20 || played != 2 {
+		t.Errorf("got %d seasons and %d matches (%d played), want 1 season and 20 matches (2 played)", count, matches, played)
+	}
+}
@@ -0,0 +1,171 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"insider-case/models"
+	"os"
+	"strconv"
+)
+
+var ErrInvalidParams = errors.New("invalid simulation parameters")
+
+// DefaultSimulationParams modelin yapılandırma verilmediğindeki değerleri
+func DefaultSimulationParams() models.SimulationParams {
+	return models.SimulationParams{
+		HomeAdvantage:       1.8,
+		BaseGoalRate:        1.0,
+		StrengthScale:       100,
+		CapGoals:            true,
+		MaxGoals:            5,
+		DixonColesRho:       -0.13,
+		BivariateCovariance: 0.15,
+		Ratings:             false,
+		EloK:                20,
+		EloHomeAdvantage:    100,
+	}
+}
+
+// configuredParams sezonda değiştirilmemiş parametrelerin değeri, uygulama başlarken ayarlanır
+var configuredParams = DefaultSimulationParams()
+
+// SetSimulationParams tüm sezonların varsayılan parametrelerini değiştirir
+func SetSimulationParams(params models.SimulationParams) error {
+	if err := ValidateSimulationParams(params); err != nil {
+		return err
+	}
+	configuredParams = params
+	return nil
+}
+
+// LoadSimulationParams varsayılan değerlerin üzerine path'teki JSON dosyasını (path boş
+// değilse) ve ardından SIM_* ortam değişkenlerini uygular. Dosyada olmayan alanlar varsayılan kalır.
+func LoadSimulationParams(path string) (models.SimulationParams, error) {
+	params := DefaultSimulationParams()
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return params, err
+		}
+		if err := decodeParams(data, &params); err != nil {
+			return params, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	floats := map[string]*float64{
+		"SIM_HOME_ADVANTAGE":       &params.HomeAdvantage,
+		"SIM_BASE_GOAL_RATE":       &params.BaseGoalRate,
+		"SIM_STRENGTH_SCALE":       &params.StrengthScale,
+		"SIM_DIXON_COLES_RHO":      &params.DixonColesRho,
+		"SIM_BIVARIATE_COVARIANCE": &params.BivariateCovariance,
+		"SIM_ELO_K":                &params.EloK,
+		"SIM_ELO_HOME_ADVANTAGE":   &params.EloHomeAdvantage,
+	}
+	for name, field := range floats {
+		if v, ok := os.LookupEnv(name); ok {
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return params, fmt.Errorf("%w: %s must be a number", ErrInvalidParams, name)
+			}
+			*field = f
+		}
+	}
+	bools := map[string]*bool{
+		"SIM_CAP_GOALS": &params.CapGoals,
+		"SIM_RATINGS":   &params.Ratings,
+	}
+	for name, field := range bools {
+		if v, ok := os.LookupEnv(name); ok {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return params, fmt.Errorf("%w: %s must be true or false", ErrInvalidParams, name)
+			}
+			*field = b
+		}
+	}
+	if v, ok := os.LookupEnv("SIM_MAX_GOALS"); ok {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return params, fmt.Errorf("%w: SIM_MAX_GOALS must be an integer", ErrInvalidParams)
+		}
+		params.MaxGoals = n
+	}
+
+	return params, ValidateSimulationParams(params)
+}
+
+// ValidateSimulationParams parametrelerin modelde anlamlı olup olmadığını kontrol eder
+func ValidateSimulationParams(p models.SimulationParams) error {
+	switch {
+	case p.HomeAdvantage <= 0:
+		return fmt.Errorf("%w: home_advantage must be positive", ErrInvalidParams)
+	case p.BaseGoalRate <= 0:
+		return fmt.Errorf("%w: base_goal_rate must be positive", ErrInvalidParams)
+	case p.StrengthScale <= 0:
+		return fmt.Errorf("%w: strength_scale must be positive", ErrInvalidParams)
+	case p.MaxGoals < 1 || p.MaxGoals > poissonTail:
+		return fmt.Errorf("%w: max_goals must be between 1 and %d", ErrInvalidParams, poissonTail)
+	case p.DixonColesRho < -1 || p.DixonColesRho > 1:
+		return fmt.Errorf("%w: dixon_coles_rho must be between -1 and 1", ErrInvalidParams)
+	case p.BivariateCovariance < 0:
+		return fmt.Errorf("%w: bivariate_covariance must not be negative", ErrInvalidParams)
+	case p.EloK <= 0:
+		return fmt.Errorf("%w: elo_k must be positive", ErrInvalidParams)
+	case p.EloHomeAdvantage < 0:
+		return fmt.Errorf("%w: elo_home_advantage must not be negative", ErrInvalidParams)
+	}
+	return nil
+}
+
+// effectiveParams sezonun JSON olarak saklanan değişikliklerini yapılandırılmış değerlerin üzerine uygular
+func effectiveParams(overrides string) (models.SimulationParams, error) {
+	params := configuredParams
+	if err := decodeParams([]byte(overrides), &params); err != nil {
+		return params, err
+	}
+	return params, nil
+}
+
+// seasonParams sezonun kullandığı simülasyon parametreleri
+func seasonParams(q dbtx, seasonID int) (models.SimulationParams, error) {
+	var overrides string
+	if err := q.QueryRow("SELECT sim_params FROM seasons WHERE id = ?", seasonID).Scan(&overrides); err != nil {
+		return models.SimulationParams{}, err
+	}
+	params, err := effectiveParams(overrides)
+	if err == nil {
+		// Yapılandırma değiştiyse sezonun eski değişiklikleri geçersiz bir kombinasyon verebilir
+		err = ValidateSimulationParams(params)
+	}
+	if err != nil {
+		return params, fmt.Errorf("season %d: %w", seasonID, err)
+	}
+	return params, nil
+}
+
+// decodeParams JSON nesnesindeki alanları params'ın üzerine yazar, bilinmeyen alanları reddeder
+func decodeParams(data []byte, params *models.SimulationParams) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(params); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidParams, err)
+	}
+	return nil
+}
+
+// goalLimit skor matrisinin en yüksek gol sayısı; tavan kapalıysa poissonTail
+func goalLimit(p models.SimulationParams) int {
+	if p.CapGoals {
+		return p.MaxGoals
+	}
+	return poissonTail
+}
+
+// scoringLambdas skor modelinin ev sahibi ve deplasman gol ortalamaları: takımın saldırısı
+// rakibin savunmasına karşı (ev sahibi avantajı da var)
+func scoringLambdas(p models.SimulationParams, home, away models.TeamStrength) (float64, float64) {
+	return p.BaseGoalRate * p.HomeAdvantage * home.Attack * away.Defence, p.BaseGoalRate * away.Attack * home.Defence
+}
@@ -2,182 +2,262 @@ package services
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"insider-case/models"
 	"math/rand"
 	"time"
 )
 
+var (
+	// ErrWeekAlreadyPlayed haftanın tüm maçları zaten oynanmışsa döner
+	ErrWeekAlreadyPlayed = errors.New("week has already been played")
+	// ErrWeekNotScheduled fikstürde o haftaya ait maç yoksa döner
+	ErrWeekNotScheduled = errors.New("week is not in the schedule")
+	// ErrWeekNotPlayed tekrar oynatılmak istenen haftanın oynanmamış maçı varsa döner
+	ErrWeekNotPlayed = errors.New("week has not been fully played")
+)
+
+// MatchResult simülasyonda oynanan bir maçın takım isimleriyle birlikte sonucu
+type MatchResult struct {
+	ID         int           `json:"id"`
+	HomeTeamID int           `json:"home_team_id"`
+	HomeTeam   string        `json:"home_team"`
+	AwayTeamID int           `json:"away_team_id"`
+	AwayTeam   string        `json:"away_team"`
+	HomeGoals  int           `json:"home_goals"`
+	AwayGoals  int           `json:"away_goals"`
+	Result     models.Result `json:"result"`
+}
+
+// WeekResult bir haftanın simülasyon sonucudur: oynanan maçlar, bay geçen
+// takımlar, hafta sonundaki puan tablosu ve hafta öncesi şampiyonluk tahminleri.
+// Engine ve Params skorların hangi model ve parametrelerle üretildiğini gösterir.
+type WeekResult struct {
+	SeasonID    int                     `json:"season_id"`
+	Week        int                     `json:"week"`
+	Seed        int64                   `json:"seed"`
+	Engine      string                  `json:"engine"`
+	Params      models.SimulationParams `json:"params"`
+	Matches     []MatchResult           `json:"matches"`
+	Byes        []string                `json:"byes"`
+	Standings   []TeamStats             `json:"standings"`
+	Predictions *ChampionshipPrediction `json:"predictions"`
+}
+
+// SeasonSummary SimulateAllWeeks ile oynanan haftaları ve sezon sonu tablosunu özetler
+type SeasonSummary struct {
+	SeasonID  int                     `json:"season_id"`
+	Seed      int64                   `json:"seed"`
+	Engine    string                  `json:"engine"`
+	Params    models.SimulationParams `json:"params"`
+	Weeks     []WeekResult            `json:"weeks"`
+	Standings []TeamStats             `json:"standings"`
+}
+
 type SimulatorService struct {
-	DB *sql.DB
+	DB       *sql.DB
+	fixtures *FixtureService
+	seasons  *SeasonService
+	matches  *MatchService
 }
 
 func NewSimulatorService(db *sql.DB) *SimulatorService {
-	return &SimulatorService{DB: db}
+	return &SimulatorService{
+		DB:       db,
+		fixtures: NewFixtureService(db),
+		seasons:  NewSeasonService(db),
+		matches:  NewMatchService(db),
+	}
 }
 
-func (s *SimulatorService) SimulateWeek(week int) error {
-	teams, err := s.getTeams()
+// NewSeed tohum verilmemiş simülasyonlar için zamana dayalı yeni bir tohum üretir.
+func NewSeed() int64 {
+	return time.Now().UnixNano()
+}
+
+// SimulateWeek sezonun verilen haftasında fikstürde planlanmış ve henüz oynanmamış maçları oynatır.
+// Skorlar seed ile oluşturulan rastgele kaynaktan üretilir ve seed haftayla birlikte
+// kaydedilir; aynı fikstür aynı seed ile tekrar oynatıldığında aynı skorları verir.
+// Haftanın tüm sonuçları ve takım istatistikleri tek bir transaction içinde yazılır;
+// herhangi bir hata olursa hiçbir değişiklik kaydedilmez. Hafta zaten oynanmışsa
+// ErrWeekAlreadyPlayed döner.
+func (s *SimulatorService) SimulateWeek(seasonID, week int, seed int64) (*WeekResult, error) {
+	return s.simulateWeek(seasonID, week, seed, false)
+}
+
+// ReplayWeek oynanmış bir haftanın maçlarını yeniden oynatır. Maçlar yerinde güncellenir,
+// ID'leri değişmez; hata olursa eski sonuçlar kalır. Haftanın oynanmamış maçı varsa
+// ErrWeekNotPlayed döner, böylece replay sıradaki haftaları atlamak için kullanılamaz.
+func (s *SimulatorService) ReplayWeek(seasonID, week int, seed int64) (*WeekResult, error) {
+	return s.simulateWeek(seasonID, week, seed, true)
+}
+
+func (s *SimulatorService) simulateWeek(seasonID, week int, seed int64, replay bool) (*WeekResult, error) {
+	// Kontroller, fikstür, tahmin ve sonuçlar aynı transaction üzerinden okunup yazılır
+	tx, err := s.DB.Begin()
 	if err != nil {
-		return err
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := requireActive(tx, seasonID); err != nil {
+		return nil, err
+	}
+	if err := ensureSchedule(tx, seasonID); err != nil {
+		return nil, err
+	}
+
+	teams, err := s.queryTeams(tx)
+	if err != nil {
+		return nil, err
 	}
 	if len(teams) < 2 {
-		return fmt.Errorf("not enough teams to simulate matches")
+		return nil, fmt.Errorf("not enough teams to simulate matches")
 	}
 
-	// Şampiyonluk oranlarını al (İSTEĞE BAĞLI)
-	champProbs, err := s.GetChampionshipProbabilities()
+	scheduled, err := matchesByWeek(tx, seasonID, week)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if len(scheduled) == 0 {
+		return nil, fmt.Errorf("week %d: %w", week, ErrWeekNotScheduled)
 	}
 
-	fmt.Printf("%d week predictions of championship\n", week)
-	for _, team := range teams {
-		prob, ok := champProbs[team.ID]
-		if !ok {
-			prob = 0.0
+	// Normal modda sadece oynanmamış maçlar oynanır, tekrar modunda haftanın tamamı
+	var fixtures []models.Match
+	for _, m := range scheduled {
+		if replay && !m.Played {
+			return nil, fmt.Errorf("week %d: %w", week, ErrWeekNotPlayed)
+		}
+		if replay || !m.Played {
+			fixtures = append(fixtures, m)
+		}
+	}
+	if len(fixtures) == 0 {
+		return nil, fmt.Errorf("week %d: %w", week, ErrWeekAlreadyPlayed)
+	}
+	if !replay {
+		if err := checkWeekOrder(tx, seasonID, week); err != nil {
+			return nil, err
 		}
-		fmt.Printf("%s: %.2f\n", team.Name, prob)
 	}
 
-	fmt.Printf("\n%d week results\n", week)
+	// Hafta oynanmadan önceki şampiyonluk tahminleri; haftanın tohumuyla oynandıkları için
+	// aynı tohumla tekrar oynatılan hafta aynı tahminleri döner
+	predictions, err := s.predictChampionship(tx, seasonID, defaultPredictionIterations, defaultPredictionTop, seed)
+	if err != nil {
+		return nil, err
+	}
 
-	// Maçları simüle et
-	rand.Shuffle(len(teams), func(i, j int) { teams[i], teams[j] = teams[j], teams[i] })
+	engine, params, err := seasonEngine(tx, seasonID)
+	if err != nil {
+		return nil, err
+	}
+	strengths, err := matchStrengths(tx, seasonID, week, params)
+	if err != nil {
+		return nil, err
+	}
 
-	stats := make(map[int]*TeamStats)
+	teamsByID := make(map[int]models.Team)
 	for _, team := range teams {
-		stats[team.ID] = &TeamStats{Team: team}
-	}
-	for i := 0; i < len(teams)-1; i += 2 {
-		home := teams[i]
-		away := teams[i+1]
-
-		homeGoals, awayGoals := s.simulateScore(home.Strength, away.Strength)
-
-		// Sonucu hesapla
-		var result string
-		if homeGoals > awayGoals {
-			result = "WIN"
-		} else if homeGoals < awayGoals {
-			result = "LOSE"
-		} else {
-			result = "DRAW"
-		}
+		teamsByID[team.ID] = team
+	}
+
+	rng := rand.New(rand.NewSource(seed))
+	for i, fixture := range fixtures {
+		homeGoals, awayGoals := engine.SimulateScore(strengths[fixture.HomeTeamID], strengths[fixture.AwayTeamID], rng)
 
-		// Maçı veritabanına ekle, result sütunuyla birlikte
-		_, err = s.DB.Exec(`
-        INSERT INTO matches (home_team_id, away_team_id, week, home_goals, away_goals, result)
-        VALUES (?, ?, ?, ?, ?, ?)`,
-			home.ID, away.ID, week, homeGoals, awayGoals, result)
+		result := models.ResultFromScore(homeGoals, awayGoals)
+
+		// Fikstürdeki maçı sonucuyla birlikte oynanmış olarak işaretle
+		_, err = tx.Exec(`
+        UPDATE matches SET home_goals = ?, away_goals = ?, result = ?, played = 1
+        WHERE id = ?`,
+			homeGoals, awayGoals, result, fixture.ID)
 		if err != nil {
-			return err
+			return nil, fmt.Errorf("week %d rolled back: %w", week, err)
 		}
 
-		// İstatistikleri güncelle
-		s.updateStats(stats[home.ID], stats[away.ID], homeGoals, awayGoals)
+		fixtures[i].HomeGoals = homeGoals
+		fixtures[i].AwayGoals = awayGoals
+		fixtures[i].Result = result
+		fixtures[i].Played = true
+	}
 
-		fmt.Printf("%s %d - %d %s\n", home.Name, homeGoals, awayGoals, away.Name)
+	_, err = tx.Exec(`
+		INSERT OR REPLACE INTO simulated_weeks (season_id, week, seed, simulated_at)
+		VALUES (?, ?, ?, CURRENT_TIMESTAMP)`,
+		seasonID, week, seed)
+	if err != nil {
+		return nil, fmt.Errorf("week %d rolled back: %w", week, err)
 	}
 
-	fmt.Printf("\nWeek %d Team Stats:\n", week)
-	fmt.Printf("%-15s %6s %6s %6s %6s %6s %6s %6s %6s\n",
-		"Team", "P", "W", "D", "L", "GF", "GA", "GD", "Pts")
+	// İstatistikleri haftanın sonuçlarıyla birlikte aynı transaction içinde güncelle
+	standings, err := s.recalculate(tx, seasonID)
+	if err != nil {
+		return nil, fmt.Errorf("week %d rolled back: %w", week, err)
+	}
 
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("week %d rolled back: %w", week, err)
+	}
+
+	result := &WeekResult{
+		SeasonID:    seasonID,
+		Week:        week,
+		Seed:        seed,
+		Engine:      engine.Name(),
+		Params:      params,
+		Byes:        []string{},
+		Standings:   standings,
+		Predictions: predictions,
+	}
+	playing := make(map[int]bool)
+	for _, m := range fixtures {
+		playing[m.HomeTeamID] = true
+		playing[m.AwayTeamID] = true
+		result.Matches = append(result.Matches, MatchResult{
+			ID:         m.ID,
+			HomeTeamID: m.HomeTeamID,
+			HomeTeam:   teamsByID[m.HomeTeamID].Name,
+			AwayTeamID: m.AwayTeamID,
+			AwayTeam:   teamsByID[m.AwayTeamID].Name,
+			HomeGoals:  m.HomeGoals,
+			AwayGoals:  m.AwayGoals,
+			Result:     m.Result,
+		})
+	}
 	for _, team := range teams {
-		st := stats[team.ID]
-		fmt.Printf("%-15s %6d %6d %6d %6d %6d %6d %6d %6d\n",
-			team.Name,
-			st.Played,
-			st.Won,
-			st.Drawn,
-			st.Lost,
-			st.GF,
-			st.GA,
-			st.GoalDiff,
-			st.Points)
-	}
-
-	return nil
+		if !playing[team.ID] {
+			result.Byes = append(result.Byes, team.Name)
+		}
+	}
+	return result, nil
 }
 
-func (s *SimulatorService) simulateScore(homeStrength, awayStrength int) (int, int) {
-	rand.Seed(time.Now().UnixNano())
+// poissonScore varsayılan skor modeli: saldırı ve savunma güçlerine göre bağımsız Poisson ile gol üretir.
+// Aynı tohumla oluşturulmuş rng aynı skorları üretir.
+func poissonScore(params models.SimulationParams, home, away models.TeamStrength, rng *rand.Rand) (int, int) {
+	homeLambda, awayLambda := scoringLambdas(params, home, away)
 
-	// Güç değerini normalize et (örnek max 100 üzerinden)
-	homeFactor := float64(homeStrength) / 100.0
-	awayFactor := float64(awayStrength) / 100.0
-
-	// Ortalama gol sayısını belirle (ev sahibi avantajı da var)
-	homeLambda := 1.8 * homeFactor
-	awayLambda := 1.0 * awayFactor
-
-	homeGoals := poisson(homeLambda)
-	awayGoals := poisson(awayLambda)
+	homeGoals := poisson(homeLambda, rng)
+	awayGoals := poisson(awayLambda, rng)
 
 	// Maksimum gol sınırı koy
-	if homeGoals > 5 {
-		homeGoals = 5
-	}
-	if awayGoals > 5 {
-		awayGoals = 5
-	}
-
-	return homeGoals, awayGoals
+	return capGoals(params, homeGoals), capGoals(params, awayGoals)
 }
 
-func (s *SimulatorService) updateStats(home, away *TeamStats, homeGoals, awayGoals int) error {
-	home.Played++
-	away.Played++
-
-	home.GF += homeGoals
-	home.GA += awayGoals
-	home.GoalDiff = home.GF - home.GA
-
-	away.GF += awayGoals
-	away.GA += homeGoals
-	away.GoalDiff = away.GF - away.GA
-
-	switch {
-	case homeGoals > awayGoals:
-		home.Won++
-		home.Points += 3
-		away.Lost++
-	case homeGoals < awayGoals:
-		away.Won++
-		away.Points += 3
-		home.Lost++
-	default:
-		home.Drawn++
-		away.Drawn++
-		home.Points++
-		away.Points++
-	}
-
-	// Veritabanına yaz
-	_, err := s.DB.Exec(`
-		UPDATE teams SET
-			played = ?, won = ?, drawn = ?, lost = ?, points = ?, gf = ?, ga = ?, gd = ?
-		WHERE id = ?`,
-		home.Played, home.Won, home.Drawn, home.Lost, home.Points, home.GF, home.GA, home.GoalDiff, home.Team.ID)
-	if err != nil {
-		return err
+// capGoals gol tavanı açıksa golleri MaxGoals'a indirir
+func capGoals(params models.SimulationParams, goals int) int {
+	if params.CapGoals && goals > params.MaxGoals {
+		return params.MaxGoals
 	}
-
-	_, err = s.DB.Exec(`
-		UPDATE teams SET
-			played = ?, won = ?, drawn = ?, lost = ?, points = ?, gf = ?, ga = ?, gd = ?
-		WHERE id = ?`,
-		away.Played, away.Won, away.Drawn, away.Lost, away.Points, away.GF, away.GA, away.GoalDiff, away.Team.ID)
-	if err != nil {
-		return err
-	}
-
-	return nil
+	return goals
 }
 
-func (s *SimulatorService) getTeams() ([]models.Team, error) {
-	rows, err := s.DB.Query("SELECT id, name, strength FROM teams")
+func (s *SimulatorService) queryTeams(q dbtx) ([]models.Team, error) {
+	rows, err := q.Query("SELECT id, name, strength, attack, defence FROM teams ORDER BY id")
 	if err != nil {
 		return nil, err
 	}
@@ -186,7 +266,7 @@ func (s *SimulatorService) getTeams() ([]models.Team, error) {
 	var teams []models.Team
 	for rows.Next() {
 		var t models.Team
-		if err := rows.Scan(&t.ID, &t.Name, &t.Strength); err != nil {
+		if err := rows.Scan(&t.ID, &t.Name, &t.Strength, &t.Attack, &t.Defence); err != nil {
 			return nil, err
 		}
 		teams = append(teams, t)
@@ -194,25 +274,50 @@ func (s *SimulatorService) getTeams() ([]models.Team, error) {
 	return teams, nil
 }
 
-func (s *SimulatorService) SimulateAllWeeks() error {
-	// Örneğin 5 hafta simüle edelim, bu sayıyı ihtiyaçlarına göre değiştir
-	const totalWeeks = 5
+// SimulateAllWeeks sezonun kalan haftalarını fikstürdeki sırayla oynatır; hafta sayısı
+// takım sayısına göre oluşturulan fikstürden gelir. Her haftanın tohumu seed ile
+// başlatılan ana kaynaktan türetilir, böylece tüm sezon tek bir seed ile tekrarlanabilir.
+func (s *SimulatorService) SimulateAllWeeks(seasonID int, seed int64) (*SeasonSummary, error) {
+	engine, params, err := seasonEngine(s.DB, seasonID)
+	if err != nil {
+		return nil, err
+	}
+	summary := &SeasonSummary{SeasonID: seasonID, Seed: seed, Engine: engine.Name(), Params: params, Weeks: []WeekResult{}}
+
+	weekSeeds := rand.New(rand.NewSource(seed))
+	for {
+		state, err := s.GetLeagueState(seasonID)
+		if err != nil {
+			return nil, err
+		}
+		if state.Finished {
+			break
+		}
 
-	for week := 1; week <= totalWeeks; week++ {
-		if err := s.SimulateWeek(week); err != nil {
-			return fmt.Errorf("failed to simulate week %d: %w", week, err)
+		result, err := s.SimulateWeek(seasonID, state.CurrentWeek, weekSeeds.Int63())
+		if err != nil {
+			return nil, fmt.Errorf("failed to simulate week %d: %w", state.CurrentWeek, err)
 		}
+		summary.Weeks = append(summary.Weeks, *result)
+	}
+
+	standings, err := s.GetCurrentStandings(seasonID)
+	if err != nil {
+		return nil, err
 	}
-	return nil
+	summary.Standings = standings
+
+	return summary, nil
 }
 
-// GetAllMatches tüm maçları döner
-func (s *SimulatorService) GetAllMatches() ([]models.Match, error) {
+// GetAllMatches sezonun tüm maçlarını döner
+func (s *SimulatorService) GetAllMatches(seasonID int) ([]models.Match, error) {
 	rows, err := s.DB.Query(`
-		SELECT id, week, home_team_id, away_team_id, home_goals, away_goals
+		SELECT id, week, home_team_id, away_team_id, home_goals, away_goals, result, played
 		FROM matches
+		WHERE season_id = ?
 		ORDER BY week, id
-	`)
+	`, seasonID)
 	if err != nil {
 		return nil, err
 	}
@@ -221,7 +326,7 @@ func (s *SimulatorService) GetAllMatches() ([]models.Match, error) {
 	var matches []models.Match
 	for rows.Next() {
 		var m models.Match
-		err := rows.Scan(&m.ID, &m.Week, &m.HomeTeamID, &m.AwayTeamID, &m.HomeGoals, &m.AwayGoals)
+		err := rows.Scan(&m.ID, &m.Week, &m.HomeTeamID, &m.AwayTeamID, &m.HomeGoals, &m.AwayGoals, &m.Result, &m.Played)
 		if err != nil {
 			return nil, err
 		}
@@ -231,14 +336,14 @@ func (s *SimulatorService) GetAllMatches() ([]models.Match, error) {
 	return matches, nil
 }
 
-// GetMatchesByWeek belirli haftaya ait maçları döner
-func (s *SimulatorService) GetMatchesByWeek(week int) ([]models.Match, error) {
-	rows, err := s.DB.Query(`
-		SELECT id, week, home_team_id, away_team_id, home_goals, away_goals
+// matchesByWeek sezonun belirli haftasına ait maçları q üzerinden döner
+func matchesByWeek(q dbtx, seasonID, week int) ([]models.Match, error) {
+	rows, err := q.Query(`
+		SELECT id, week, home_team_id, away_team_id, home_goals, away_goals, result, played
 		FROM matches
-		WHERE week = ?
+		WHERE season_id = ? AND week = ?
 		ORDER BY id
-	`, week)
+	`, seasonID, week)
 	if err != nil {
 		return nil, err
 	}
@@ -247,7 +352,7 @@ func (s *SimulatorService) GetMatchesByWeek(week int) ([]models.Match, error) {
 	var matches []models.Match
 	for rows.Next() {
 		var m models.Match
-		err := rows.Scan(&m.ID, &m.Week, &m.HomeTeamID, &m.AwayTeamID, &m.HomeGoals, &m.AwayGoals)
+		err := rows.Scan(&m.ID, &m.Week, &m.HomeTeamID, &m.AwayTeamID, &m.HomeGoals, &m.AwayGoals, &m.Result, &m.Played)
 		if err != nil {
 			return nil, err
 		}
@@ -256,41 +361,3 @@ func (s *SimulatorService) GetMatchesByWeek(week int) ([]models.Match, error) {
 
 	return matches, nil
 }
-
-func (s *SimulatorService) GetPointsUpToWeek(teamID, week int) (int, error) {
-	var points int
-	query := `
-        SELECT COALESCE(SUM(
-            CASE 
-                WHEN (home_team_id = ? AND home_goals > away_goals) OR (away_team_id = ? AND away_goals > home_goals) THEN 3
-                WHEN home_goals = away_goals THEN 1
-                ELSE 0
-            END), 0)
-        FROM matches
-        WHERE (home_team_id = ? OR away_team_id = ?) AND week < ?
-    `
-	err := s.DB.QueryRow(query, teamID, teamID, teamID, teamID, week).Scan(&points)
-	if err != nil {
-		return 0, err
-	}
-	return points, nil
-}
-
-func (s *SimulatorService) GetTotalPoints(teamID int) (int, error) {
-	var points int
-	query := `
-        SELECT COALESCE(SUM(
-            CASE 
-                WHEN (home_team_id = ? AND home_goals > away_goals) OR (away_team_id = ? AND away_goals > home_goals) THEN 3
-                WHEN home_goals = away_goals THEN 1
-                ELSE 0
-            END), 0)
-        FROM matches
-        WHERE home_team_id = ? OR away_team_id = ?
-    `
-	err := s.DB.QueryRow(query, teamID, teamID, teamID, teamID).Scan(&points)
-	if err != nil {
-		return 0, err
-	}
-	return points, nil
-}
@@ -0,0 +1,127 @@
+package services
+
+import (
+	"errors"
+	"insider-case/models"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// weekScores haftanın skorlarını maç ID'leriyle birlikte döner
+func weekScores(result *WeekResult) [][3]int {
+	scores := make([][3]int, len(result.Matches))
+	for i, m := range result.Matches {
+		scores[i] = [3]int{m.ID, m.HomeGoals, m.AwayGoals}
+	}
+	return scores
+}
+
+func TestSimulateWeekIsReproducible(t *testing.T) {
+	tests := []struct {
+		engine string
+		params string
+		seed   int64
+	}{
+		{EnginePoisson, `{}`, 1},
+		{EnginePoisson, `{"cap_goals": false}`, 42},
+		{EngineDixonColes, `{}`, 7},
+		{EngineBivariatePoisson, `{}`, 2024},
+		{EnginePoisson, `{"ratings": true}`, 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.engine+" "+tt.params, func(t *testing.T) {
+			// İki bağımsız veritabanında aynı tohumlar aynı sezonu vermeli
+			var runs [2][][][3]int
+			var simulators [2]*SimulatorService
+			for run := range runs {
+				db := newTestDB(t)
+				seasons := NewSeasonService(db)
+				if _, err := seasons.SetEngine(1, tt.engine); err != nil {
+					t.Fatal(err)
+				}
+				if _, err := seasons.SetParams(1, []byte(tt.params)); err != nil {
+					t.Fatal(err)
+				}
+
+				simulators[run] = NewSimulatorService(db)
+				for week := 1; week <= 4; week++ {
+					result, err := simulators[run].SimulateWeek(1, week, tt.seed+int64(week))
+					if err != nil {
+						t.Fatalf("week %d: %v", week, err)
+					}
+					runs[run] = append(runs[run], weekScores(result))
+				}
+			}
+			if !reflect.DeepEqual(runs[0], runs[1]) {
+				t.Fatalf("same seeds gave different seasons:\n%v\n%v", runs[0], runs[1])
+			}
+
+			// Oynanmış bir haftayı kayıtlı tohumla tekrar oynatmak aynı skorları ve ID'leri verir
+			replayed, err := simulators[0].ReplayWeek(1, 2, tt.seed+2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := weekScores(replayed); !reflect.DeepEqual(got, runs[0][1]) {
+				t.Errorf("replay of week 2 = %v, want %v", got, runs[0][1])
+			}
+		})
+	}
+}
+
+func TestSimulateWeekRejectsOutOfOrderWeeks(t *testing.T) {
+	simulator := NewSimulatorService(newTestDB(t))
+
+	if _, err := simulator.SimulateWeek(1, 3, 1); !errors.Is(err, ErrWeekOutOfOrder) {
+		t.Errorf("week 3 before week 1: got %v, want %v", err, ErrWeekOutOfOrder)
+	}
+	if _, err := simulator.ReplayWeek(1, 3, 1); !errors.Is(err, ErrWeekNotPlayed) {
+		t.Errorf("replay of an unplayed week: got %v, want %v", err, ErrWeekNotPlayed)
+	}
+	if _, err := simulator.SimulateWeek(1, 1, 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := simulator.SimulateWeek(1, 1, 1); !errors.Is(err, ErrWeekAlreadyPlayed) {
+		t.Errorf("week 1 twice: got %v, want %v", err, ErrWeekAlreadyPlayed)
+	}
+}
+
+func TestSimulateWeekStoresResults(t *testing.T) {
+	simulator := NewSimulatorService(newTestDB(t))
+
+	result, err := simulator.SimulateWeek(1, 1, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range result.Matches {
+		if want := models.ResultFromScore(m.HomeGoals, m.AwayGoals); m.Result != want {
+			t.Errorf("match %d %d-%d: result %q, want %q", m.ID, m.HomeGoals, m.AwayGoals, m.Result, want)
+		}
+	}
+}
+
+func TestSimulateWeekRunsInOneTransaction(t *testing.T) {
+	db := newTestDB(t)
+	// Tek bağlantıyla, transaction dışından yapılan her okuma ya da yazma kilitlenirdi
+	db.SetMaxOpenConns(1)
+	simulator := NewSimulatorService(db)
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := simulator.SimulateWeek(1, 1, 1)
+		if err == nil {
+			_, err = simulator.ReplayWeek(1, 1, 1)
+		}
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("simulating a week used a second connection")
+	}
+}
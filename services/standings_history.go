@@ -0,0 +1,118 @@
+package services
+
+import "sort"
+
+// WeekPosition takımın bir hafta sonundaki sırası ve puanı
+type WeekPosition struct {
+	Week     int `json:"week"`
+	Position int `json:"position"`
+	Points   int `json:"points"`
+}
+
+// TeamPositionHistory takımın oynanan haftalar boyunca sıralaması
+type TeamPositionHistory struct {
+	TeamID    int            `json:"team_id"`
+	Name      string         `json:"name"`
+	Positions []WeekPosition `json:"positions"`
+}
+
+// PositionHistory sezonun hafta hafta sıralama geçmişi; takımlar son haftadaki sıraya göre dizilir
+type PositionHistory struct {
+	SeasonID int                   `json:"season_id"`
+	Weeks    []int                 `json:"weeks"`
+	Teams    []TeamPositionHistory `json:"teams"`
+}
+
+// GetPositionHistory sezonun standings_history tablosundaki sıralama geçmişini döner
+func (s *SimulatorService) GetPositionHistory(seasonID int) (*PositionHistory, error) {
+	rows, err := s.DB.Query(`
+		SELECT h.week, h.team_id, t.name, h.position, h.points
+		FROM standings_history h
+		JOIN teams t ON t.id = h.team_id
+		WHERE h.season_id = ?
+		ORDER BY h.week, h.position
+	`, seasonID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	history := &PositionHistory{SeasonID: seasonID, Weeks: []int{}, Teams: []TeamPositionHistory{}}
+	byTeam := make(map[int]*TeamPositionHistory)
+	var order []int
+	for rows.Next() {
+		var teamID int
+		var name string
+		var p WeekPosition
+		if err := rows.Scan(&p.Week, &teamID, &name, &p.Position, &p.Points); err != nil {
+			return nil, err
+		}
+
+		if n := len(history.Weeks); n == 0 || history.Weeks[n-1] != p.Week {
+			history.Weeks = append(history.Weeks, p.Week)
+		}
+		team, ok := byTeam[teamID]
+		if !ok {
+			team = &TeamPositionHistory{TeamID: teamID, Name: name}
+			byTeam[teamID] = team
+			order = append(order, teamID)
+		}
+		team.Positions = append(team.Positions, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, id := range order {
+		history.Teams = append(history.Teams, *byTeam[id])
+	}
+	sort.SliceStable(history.Teams, func(i, j int) bool {
+		a, b := history.Teams[i].Positions, history.Teams[j].Positions
+		return a[len(a)-1].Position < b[len(b)-1].Position
+	})
+	return history, nil
+}
+
+// rebuildPositionHistory sezonun sıralama geçmişini oynanmış maçlardan baştan hesaplar.
+// Tekrar oynatılan bir hafta sonraki haftaların sırasını da değiştirebileceğinden
+// sadece son hafta değil, oynanmış her hafta yeniden yazılır.
+func (s *SimulatorService) rebuildPositionHistory(q dbtx, seasonID int) error {
+	if _, err := q.Exec("DELETE FROM standings_history WHERE season_id = ?", seasonID); err != nil {
+		return err
+	}
+
+	rows, err := q.Query("SELECT DISTINCT week FROM matches WHERE season_id = ? AND played = 1 ORDER BY week", seasonID)
+	if err != nil {
+		return err
+	}
+	var weeks []int
+	for rows.Next() {
+		var week int
+		if err := rows.Scan(&week); err != nil {
+			rows.Close()
+			return err
+		}
+		weeks = append(weeks, week)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, week := range weeks {
+		standings, err := s.computeStandings(q, seasonID, week)
+		if err != nil {
+			return err
+		}
+		for _, stat := range standings {
+			_, err := q.Exec(`
+				INSERT INTO standings_history (season_id, week, team_id, position, points)
+				VALUES (?, ?, ?, ?, ?)`,
+				seasonID, week, stat.Team.ID, stat.Position, stat.Points)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -1,80 +1,164 @@
 package services
 
 import (
+	"errors"
+	"fmt"
 	"insider-case/models"
-	"sort"
 )
 
+// Form harfleri
+const (
+	FormWin  = "W"
+	FormDraw = "D"
+	FormLoss = "L"
+)
+
+// Tablo görünümleri: tüm maçlar, sadece iç saha, sadece deplasman
+const (
+	ViewOverall = "overall"
+	ViewHome    = "home"
+	ViewAway    = "away"
+)
+
+var ErrInvalidView = errors.New("'view' must be one of overall, home, away")
+
+// VenueStats bir takımın belirli maçlardaki (tümü, iç saha veya deplasman) istatistikleri
+type VenueStats struct {
+	Played   int `json:"played"`
+	Won      int `json:"won"`
+	Drawn    int `json:"drawn"`
+	Lost     int `json:"lost"`
+	GF       int `json:"gf"`
+	GA       int `json:"ga"`
+	Points   int `json:"points"`
+	GoalDiff int `json:"goal_diff"`
+}
+
 type TeamStats struct {
-	Team     models.Team
-	Played   int
-	Won      int
-	Drawn    int
-	Lost     int
-	GF       int
-	GA       int
-	Points   int
-	GoalDiff int
+	Team models.Team `json:"team"`
+	VenueStats
+	Position int      `json:"position"`
+	Form     []string `json:"form"` // Son formLength maç eskiden yeniye, örn. ["W", "D", "L"]
+	// Tiebreaker puanı eşit komşusundan bu takımı ayıran kural, puanı kimseyle eşit değilse boş
+	Tiebreaker string `json:"tiebreaker,omitempty"`
+	// Genel tabloda takımın iç saha ve deplasman dağılımı; home/away tablolarında boş
+	Home *VenueStats `json:"home,omitempty"`
+	Away *VenueStats `json:"away,omitempty"`
+	// FormGuide form_length maçlık form ve seriler, sadece AddFormGuides çağrıldığında (?include=form) doldurulur
+	FormGuide *FormGuide `json:"form_guide,omitempty"`
+
+	results []string // sezonun tüm sonuçları, hafta sırasıyla
 }
 
-func (s *SimulatorService) GetCurrentStandings() ([]TeamStats, error) {
-	rows, err := s.DB.Query(`
-		SELECT home_team_id, away_team_id, home_goals, away_goals
-		FROM matches
-	`)
+// GetCurrentStandings sezonun oynanmış maçlarından puan tablosunu hesaplar
+func (s *SimulatorService) GetCurrentStandings(seasonID int) ([]TeamStats, error) {
+	return s.refreshStandings(s.DB, seasonID)
+}
+
+// GetStandings sezonun verilen görünümdeki tablosunu maçlardan hesaplar. week 0 değilse
+// tablo o haftanın sonundaki haliyle, sadece o haftaya kadar oynanmış maçlardan kurulur.
+// Güncel genel tablo dışındaki tablolar teams tablosuna yazılmaz.
+func (s *SimulatorService) GetStandings(seasonID, week int, view string) ([]TeamStats, error) {
+	if view != ViewOverall && view != ViewHome && view != ViewAway {
+		return nil, ErrInvalidView
+	}
+	if week == 0 && view == ViewOverall {
+		return s.GetCurrentStandings(seasonID)
+	}
+
+	if week != 0 {
+		var scheduled int
+		err := s.DB.QueryRow("SELECT COUNT(*) FROM matches WHERE season_id = ? AND week = ?", seasonID, week).Scan(&scheduled)
+		if err != nil {
+			return nil, err
+		}
+		if scheduled == 0 {
+			return nil, fmt.Errorf("week %d: %w", week, ErrWeekNotScheduled)
+		}
+	}
+	return s.computeViewStandings(s.DB, seasonID, week, view)
+}
+
+// refreshStandings tabloyu q üzerinden hesaplar. teams tablosundaki istatistikler
+// güncel sezonu yansıtır; geçmiş sezonların tablosu sadece hesaplanıp döndürülür.
+func (s *SimulatorService) refreshStandings(q dbtx, seasonID int) ([]TeamStats, error) {
+	standings, err := s.computeStandings(q, seasonID, 0)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
 
-	teams, err := s.getTeams()
+	current, err := currentSeason(q)
+	if err != nil && err != ErrNoActiveSeason {
+		return nil, err
+	}
+	if current == seasonID {
+		if err := s.updateTeamsStatsInDB(q, standings); err != nil {
+			return nil, err
+		}
+	}
+
+	return standings, nil
+}
+
+// computeStandings sezonun oynanmış maçlarını toplayıp sezonun averaj kurallarına göre sıralı
+// genel tabloyu döner ve sıraları atar. upToWeek 0 değilse sadece o haftaya kadarki maçlar sayılır.
+func (s *SimulatorService) computeStandings(q dbtx, seasonID, upToWeek int) ([]TeamStats, error) {
+	return s.computeViewStandings(q, seasonID, upToWeek, ViewOverall)
+}
+
+// computeViewStandings computeStandings gibidir; home ve away görünümlerinde takımlara
+// sadece kendi iç saha ya da deplasman maçları sayılır
+func (s *SimulatorService) computeViewStandings(q dbtx, seasonID, upToWeek int, view string) ([]TeamStats, error) {
+	teams, err := s.queryTeams(q)
+	if err != nil {
+		return nil, err
+	}
+	rules, lotsSeed, err := seasonTiebreakers(q, seasonID)
+	if err != nil {
+		return nil, err
+	}
+
+	matches, err := playedMatches(q, seasonID, upToWeek)
+	if err != nil {
+		return nil, err
+	}
+	fairPlay, err := seasonFairPlay(q, seasonID)
 	if err != nil {
 		return nil, err
 	}
 
 	stats := make(map[int]*TeamStats)
+	teamIDs := make([]int, 0, len(teams))
 	for _, t := range teams {
-		stats[t.ID] = &TeamStats{Team: t}
+		t.FairPlay = fairPlay[t.ID]
+		stats[t.ID] = &TeamStats{Team: t, Form: []string{}}
+		if view == ViewOverall {
+			stats[t.ID].Home = &VenueStats{}
+			stats[t.ID].Away = &VenueStats{}
+		}
+		teamIDs = append(teamIDs, t.ID)
 	}
 
-	for rows.Next() {
-		var homeID, awayID, homeGoals, awayGoals int
-		if err := rows.Scan(&homeID, &awayID, &homeGoals, &awayGoals); err != nil {
-			return nil, err
-		}
+	ctx := &tiebreakContext{lots: drawLotOrder(teamIDs, lotsSeed), matches: matches}
+	for _, m := range matches {
+		homeStats := stats[m.home]
+		awayStats := stats[m.away]
 
-		homeStats := stats[homeID]
-		awayStats := stats[awayID]
-
-		homeStats.Played++
-		awayStats.Played++
-		homeStats.GF += homeGoals
-		homeStats.GA += awayGoals
-		awayStats.GF += awayGoals
-		awayStats.GA += homeGoals
-
-		homeStats.GoalDiff = homeStats.GF - homeStats.GA
-		awayStats.GoalDiff = awayStats.GF - awayStats.GA
-
-		switch {
-		case homeGoals > awayGoals:
-			homeStats.Won++
-			homeStats.Points += 3
-			awayStats.Lost++
-		case homeGoals < awayGoals:
-			awayStats.Won++
-			awayStats.Points += 3
-			homeStats.Lost++
-		default:
-			homeStats.Drawn++
-			homeStats.Points++
-			awayStats.Drawn++
-			awayStats.Points++
+		if view != ViewAway {
+			homeStats.addResult(homeStats.add(m.homeGoals, m.awayGoals))
+		}
+		if view != ViewHome {
+			awayStats.addResult(awayStats.add(m.awayGoals, m.homeGoals))
+		}
+		if view == ViewOverall {
+			homeStats.Home.add(m.homeGoals, m.awayGoals)
+			awayStats.Away.add(m.awayGoals, m.homeGoals)
 		}
 	}
 
 	var standings []TeamStats
-	for _, s := range stats {
+	for _, id := range teamIDs {
+		s := stats[id]
 		// Güncellenmiş istatistikleri Team struct'una da kopyala
 		s.Team.Played = s.Played
 		s.Team.Won = s.Won
@@ -88,28 +172,75 @@ func (s *SimulatorService) GetCurrentStandings() ([]TeamStats, error) {
 		standings = append(standings, *s)
 	}
 
-	sort.SliceStable(standings, func(i, j int) bool {
-		if standings[i].Points != standings[j].Points {
-			return standings[i].Points > standings[j].Points
-		}
-		if standings[i].GoalDiff != standings[j].GoalDiff {
-			return standings[i].GoalDiff > standings[j].GoalDiff
-		}
-		return standings[i].GF > standings[j].GF
-	})
+	rankStandings(standings, rules, ctx)
+	for i := range standings {
+		standings[i].Position = i + 1
+		standings[i].Team.Position = i + 1
+	}
+	return standings, nil
+}
 
-	// İstatistikleri güncelledikten sonra veritabanını da güncelle
-	if err := s.updateTeamsStatsInDB(stats); err != nil {
+// playedMatches sezonun oynanmış maçlarını hafta sırasıyla okur; upToWeek 0 değilse
+// sadece o haftaya kadarki maçlar döner
+func playedMatches(q dbtx, seasonID, upToWeek int) ([]playedMatch, error) {
+	rows, err := q.Query(`
+		SELECT home_team_id, away_team_id, home_goals, away_goals
+		FROM matches
+		WHERE season_id = ? AND played = 1 AND (? = 0 OR week <= ?)
+		ORDER BY week, id
+	`, seasonID, upToWeek, upToWeek)
+	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
-	return standings, nil
+	var matches []playedMatch
+	for rows.Next() {
+		var m playedMatch
+		if err := rows.Scan(&m.home, &m.away, &m.homeGoals, &m.awayGoals); err != nil {
+			return nil, err
+		}
+		matches = append(matches, m)
+	}
+	return matches, rows.Err()
+}
+
+// add bir maçı takımın istatistiklerine ekler ve form harfini döner
+func (v *VenueStats) add(goalsFor, goalsAgainst int) string {
+	v.Played++
+	v.GF += goalsFor
+	v.GA += goalsAgainst
+	v.GoalDiff = v.GF - v.GA
+
+	switch {
+	case goalsFor > goalsAgainst:
+		v.Won++
+		v.Points += 3
+		return FormWin
+	case goalsFor < goalsAgainst:
+		v.Lost++
+		return FormLoss
+	default:
+		v.Drawn++
+		v.Points++
+		return FormDraw
+	}
+}
+
+// addResult maçın form harfini takımın sonuçlarına ve forma ekler, formda sadece son formLength maç tutulur
+func (t *TeamStats) addResult(result string) {
+	t.results = append(t.results, result)
+	t.Form = append(t.Form, result)
+	if len(t.Form) > formLength {
+		t.Form = t.Form[len(t.Form)-formLength:]
+	}
 }
 
-func (s *SimulatorService) updateTeamsStatsInDB(stats map[int]*TeamStats) error {
-	for _, stat := range stats {
-		_, err := s.DB.Exec(`
+func (s *SimulatorService) updateTeamsStatsInDB(q dbtx, standings []TeamStats) error {
+	for _, stat := range standings {
+		_, err := q.Exec(`
 			UPDATE teams SET
+				position = ?,
 				played = ?,
 				won = ?,
 				drawn = ?,
@@ -119,6 +250,7 @@ func (s *SimulatorService) updateTeamsStatsInDB(stats map[int]*TeamStats) error
 				ga = ?,
 				gd = ?
 			WHERE id = ?`,
+			stat.Position,
 			stat.Played,
 			stat.Won,
 			stat.Drawn,
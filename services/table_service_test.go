@@ -0,0 +1,32 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStandingsIncludeForm(t *testing.T) {
+	db := newTestDB(t)
+	simulator := NewSimulatorService(db)
+	for week := 1; week <= 7; week++ {
+		if _, err := simulator.SimulateWeek(1, week, int64(week)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, week := range []int{0, 2} {
+		standings, err := simulator.GetStandings(1, week, ViewOverall)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, row := range standings {
+			want := row.results[max(len(row.results)-formLength, 0):]
+			if row.Played != len(row.results) || !reflect.DeepEqual(row.Form, want) {
+				t.Errorf("week %d, %s: form %v after %d matches, want %v", week, row.Team.Name, row.Form, row.Played, want)
+			}
+			if row.FormGuide != nil {
+				t.Errorf("week %d, %s: form guide is set without include=form", week, row.Team.Name)
+			}
+		}
+	}
+}
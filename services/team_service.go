@@ -0,0 +1,365 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"insider-case/models"
+	"slices"
+	"strings"
+)
+
+const (
+	MinStrength = 1
+	MaxStrength = 100 // simülasyon güçleri 100 üzerinden normalize eder
+)
+
+var (
+	ErrTeamNotFound   = errors.New("team not found")
+	ErrInvalidTeam    = errors.New("invalid team")
+	ErrTeamNameTaken  = errors.New("team name already exists")
+	ErrTeamHasMatches = errors.New("team has played matches")
+)
+
+type TeamService struct {
+	DB      *sql.DB
+	seasons *SeasonService
+}
+
+func NewTeamService(db *sql.DB) *TeamService {
+	return &TeamService{
+		DB:      db,
+		seasons: NewSeasonService(db),
+	}
+}
+
+// teamColumns takım satırını okur; fair_play güncel sezonun puanıdır
+const teamColumns = `id, name, position, played, won, drawn, lost, gf, ga, gd, points, strength,
+	COALESCE((
+		SELECT f.points FROM team_fair_play f
+		WHERE f.team_id = teams.id
+			AND f.season_id = (SELECT id FROM seasons WHERE status = 'active' ORDER BY id DESC LIMIT 1)
+	), 0),
+	attack, defence`
+
+func scanTeam(row interface{ Scan(...any) error }) (models.Team, error) {
+	var t models.Team
+	err := row.Scan(&t.ID, &t.Name, &t.Position, &t.Played, &t.Won, &t.Drawn, &t.Lost,
+		&t.GF, &t.GA, &t.GD, &t.Points, &t.Strength, &t.FairPlay, &t.Attack, &t.Defence)
+	return t, err
+}
+
+// List tüm takımları ID sırasıyla döner
+func (t *TeamService) List() ([]models.Team, error) {
+	rows, err := t.DB.Query("SELECT " + teamColumns + " FROM teams ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	teams := []models.Team{}
+	for rows.Next() {
+		team, err := scanTeam(rows)
+		if err != nil {
+			return nil, err
+		}
+		teams = append(teams, team)
+	}
+	return teams, rows.Err()
+}
+
+// Get verilen ID'li takımı döner, yoksa ErrTeamNotFound
+func (t *TeamService) Get(id int) (*models.Team, error) {
+	team, err := scanTeam(t.DB.QueryRow("SELECT "+teamColumns+" FROM teams WHERE id = ?", id))
+	if err == sql.ErrNoRows {
+		return nil, ErrTeamNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &team, nil
+}
+
+// Create yeni takım ekler ve güncel sezonun fikstürünü yeni takımla yeniden oluşturur.
+// Güncel sezonda oynanmış maç varsa fikstür kilitli olduğundan takım eklenemez.
+func (t *TeamService) Create(name string, strength, attack, defence int) (*models.Team, error) {
+	name, err := t.validate(0, name, strength, attack, defence)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := t.currentSeasonID()
+	if err != nil {
+		return nil, err
+	}
+	if current != 0 {
+		if err := t.requireNoPlayedMatches(current); err != nil {
+			return nil, err
+		}
+	}
+
+	// Takım ve yeni fikstür aynı transaction içinde yazılır; fikstür oluşturulamazsa takım da eklenmez
+	tx, err := t.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec("INSERT INTO teams (name, strength, attack, defence) VALUES (?, ?, ?, ?)",
+		name, strength, attack, defence)
+	if err != nil {
+		return nil, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	if current != 0 {
+		if err := regenerateSchedule(tx, current); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return t.Get(int(id))
+}
+
+// Update takımın adını, gücünü, saldırı ve savunma güçlerini günceller. Takımın güncel
+// sezonda oynanmış maçı varsa ErrTeamHasMatches döner.
+func (t *TeamService) Update(id int, name string, strength, attack, defence int) (*models.Team, error) {
+	if _, err := t.Get(id); err != nil {
+		return nil, err
+	}
+
+	name, err := t.validate(id, name, strength, attack, defence)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := t.currentSeasonID()
+	if err != nil {
+		return nil, err
+	}
+	if current != 0 {
+		played, err := t.countPlayedMatches(id, current)
+		if err != nil {
+			return nil, err
+		}
+		if played > 0 {
+			return nil, ErrTeamHasMatches
+		}
+	}
+
+	tx, err := t.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec("UPDATE teams SET name = ?, strength = ?, attack = ?, defence = ? WHERE id = ?",
+		name, strength, attack, defence, id)
+	if err != nil {
+		return nil, err
+	}
+	// Takım güncel sezonda henüz oynamadığından sezon başı puanı yeni güce göre yazılır
+	if current != 0 {
+		if err := setInitialRating(tx, current, id, strength); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return t.Get(id)
+}
+
+// SetFairPlay takımın verilen sezondaki disiplin puanını yazar ve takımı o sezonun puanıyla
+// döner. Puan maç sonuçlarından bağımsız olduğundan sezon ortasında da güncellenebilir;
+// "fair_play" averaj kuralında sadece o sezonun tablosunda kullanılır. Arşivlenmiş sezonların
+// puanları değiştirilemez.
+func (t *TeamService) SetFairPlay(seasonID, id, points int) (*models.Team, error) {
+	if points < 0 {
+		return nil, fmt.Errorf("%w: fair_play must not be negative", ErrInvalidTeam)
+	}
+	team, err := t.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.seasons.RequireActive(seasonID); err != nil {
+		return nil, err
+	}
+
+	_, err = t.DB.Exec(`
+		INSERT INTO team_fair_play (season_id, team_id, points) VALUES (?, ?, ?)
+		ON CONFLICT (season_id, team_id) DO UPDATE SET points = excluded.points`,
+		seasonID, id, points)
+	if err != nil {
+		return nil, err
+	}
+	team.FairPlay = points
+	return team, nil
+}
+
+// Delete takımı ve oynanmamış fikstür maçlarını siler; takımın fikstürü bulunan her aktif
+// sezonun ve güncel sezonun fikstürü aynı transaction içinde yeniden oluşturulur. Oynanmış
+// maçı olan takımlar silinemez; aksi halde geçmiş sezonların tabloları bozulurdu. Bu
+// sezonlardan birinde oynanmış maç varsa fikstürü kilitli olduğundan (bay geçen takım dahil)
+// takım silinemez.
+func (t *TeamService) Delete(id int) error {
+	if _, err := t.Get(id); err != nil {
+		return err
+	}
+
+	played, err := t.countPlayedMatches(id, 0)
+	if err != nil {
+		return err
+	}
+	if played > 0 {
+		return ErrTeamHasMatches
+	}
+
+	seasons, err := t.activeSeasonsWithFixtures(id)
+	if err != nil {
+		return err
+	}
+	current, err := t.currentSeasonID()
+	if err != nil {
+		return err
+	}
+	if current != 0 && !slices.Contains(seasons, current) {
+		seasons = append(seasons, current)
+	}
+	for _, seasonID := range seasons {
+		if err := t.requireNoPlayedMatches(seasonID); err != nil {
+			return fmt.Errorf("season %d: %w", seasonID, err)
+		}
+	}
+
+	tx, err := t.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec("DELETE FROM matches WHERE played = 0 AND (home_team_id = ? OR away_team_id = ?)", id, id)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM standings_history WHERE team_id = ?", id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM team_ratings WHERE team_id = ?", id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM team_fair_play WHERE team_id = ?", id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM teams WHERE id = ?", id); err != nil {
+		return err
+	}
+	for _, seasonID := range seasons {
+		if err := regenerateSchedule(tx, seasonID); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// validate isim ve güç değerlerini doğrular, temizlenmiş ismi döner.
+// id, güncellenen takımın kendi ismiyle çakışmaması için hariç tutulur.
+func (t *TeamService) validate(id int, name string, strength, attack, defence int) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", fmt.Errorf("%w: name is required", ErrInvalidTeam)
+	}
+	if strength < MinStrength || strength > MaxStrength {
+		return "", fmt.Errorf("%w: strength must be between %d and %d", ErrInvalidTeam, MinStrength, MaxStrength)
+	}
+	if attack < MinStrength || attack > MaxStrength {
+		return "", fmt.Errorf("%w: attack must be between %d and %d", ErrInvalidTeam, MinStrength, MaxStrength)
+	}
+	if defence < MinStrength || defence > MaxStrength {
+		return "", fmt.Errorf("%w: defence must be between %d and %d", ErrInvalidTeam, MinStrength, MaxStrength)
+	}
+
+	var count int
+	err := t.DB.QueryRow("SELECT COUNT(*) FROM teams WHERE LOWER(name) = LOWER(?) AND id != ?", name, id).Scan(&count)
+	if err != nil {
+		return "", err
+	}
+	if count > 0 {
+		return "", ErrTeamNameTaken
+	}
+	return name, nil
+}
+
+// currentSeasonID güncel aktif sezonun ID'sini, aktif sezon yoksa 0 döner
+func (t *TeamService) currentSeasonID() (int, error) {
+	season, err := t.seasons.Current()
+	if err == ErrNoActiveSeason {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return season.ID, nil
+}
+
+// activeSeasonsWithFixtures takımın oynanmamış fikstür maçı bulunan aktif sezonları döner
+func (t *TeamService) activeSeasonsWithFixtures(teamID int) ([]int, error) {
+	rows, err := t.DB.Query(`
+		SELECT DISTINCT m.season_id FROM matches m
+		JOIN seasons s ON s.id = m.season_id
+		WHERE s.status = ? AND m.played = 0 AND (m.home_team_id = ? OR m.away_team_id = ?)
+		ORDER BY m.season_id`, models.SeasonActive, teamID, teamID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var seasonIDs []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		seasonIDs = append(seasonIDs, id)
+	}
+	return seasonIDs, rows.Err()
+}
+
+// countPlayedMatches takımın oynanmış maç sayısını döner; seasonID 0 ise tüm sezonlar sayılır
+func (t *TeamService) countPlayedMatches(teamID, seasonID int) (int, error) {
+	var count int
+	err := t.DB.QueryRow(`
+		SELECT COUNT(*) FROM matches
+		WHERE played = 1 AND (home_team_id = ? OR away_team_id = ?) AND (? = 0 OR season_id = ?)`,
+		teamID, teamID, seasonID, seasonID).Scan(&count)
+	return count, err
+}
+
+// countActivePlayedMatches takımın aktif sezonlardaki oynanmış maç sayısını döner
+func (t *TeamService) countActivePlayedMatches(teamID int) (int, error) {
+	var count int
+	err := t.DB.QueryRow(`
+		SELECT COUNT(*) FROM matches m
+		JOIN seasons s ON s.id = m.season_id
+		WHERE s.status = ? AND m.played = 1 AND (m.home_team_id = ? OR m.away_team_id = ?)`,
+		models.SeasonActive, teamID, teamID).Scan(&count)
+	return count, err
+}
+
+// requireNoPlayedMatches sezonda oynanmış maç varsa ErrScheduleLocked döner
+func (t *TeamService) requireNoPlayedMatches(seasonID int) error {
+	var count int
+	err := t.DB.QueryRow("SELECT COUNT(*) FROM matches WHERE season_id = ? AND played = 1", seasonID).Scan(&count)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrScheduleLocked
+	}
+	return nil
+}
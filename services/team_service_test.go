@@ -0,0 +1,139 @@
+package services
+
+import (
+	"errors"
+	"testing"
+)
+
+// seasonFixtures sezonun maç sayısını, son haftasını ve teamID'nin maç sayısını döner
+func seasonFixtures(t *testing.T, s *TeamService, seasonID, teamID int) (total, lastWeek, team int) {
+	t.Helper()
+	err := s.DB.QueryRow(`
+		SELECT COUNT(*), COALESCE(MAX(week), 0), COUNT(CASE WHEN home_team_id = ? OR away_team_id = ? THEN 1 END)
+		FROM matches WHERE season_id = ?`, teamID, teamID, seasonID).Scan(&total, &lastWeek, &team)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return total, lastWeek, team
+}
+
+func TestDeleteTeamRegeneratesEveryActiveSeason(t *testing.T) {
+	db := newTestDB(t)
+	if err := NewFixtureService(db).EnsureSchedule(1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewSeasonService(db).Create("", nil, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	teams := NewTeamService(db)
+	if err := teams.Delete(5); err != nil {
+		t.Fatal(err)
+	}
+	// Dört takımda altı hafta, haftada iki maç
+	for _, seasonID := range []int{1, 2} {
+		total, lastWeek, team := seasonFixtures(t, teams, seasonID, 5)
+		if total != 12 || lastWeek != 6 || team != 0 {
+			t.Errorf("season %d: %d fixtures over %d weeks, %d of them for the deleted team; want 12, 6 and 0",
+				seasonID, total, lastWeek, team)
+		}
+	}
+}
+
+func TestDeleteTeamRejectedWhenAnotherActiveSeasonStarted(t *testing.T) {
+	db := newTestDB(t)
+	simulator := NewSimulatorService(db)
+	week, err := simulator.SimulateWeek(1, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewSeasonService(db).Create("", nil, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	// İlk haftada bay geçen takımın oynanmış maçı yoktur ama eski sezonun fikstürü kilitlidir
+	idle := map[int]bool{1: true, 2: true, 3: true, 4: true, 5: true}
+	for _, m := range week.Matches {
+		delete(idle, m.HomeTeamID)
+		delete(idle, m.AwayTeamID)
+	}
+	var bye int
+	for id := range idle {
+		bye = id
+	}
+
+	teams := NewTeamService(db)
+	if err := teams.Delete(bye); !errors.Is(err, ErrScheduleLocked) {
+		t.Fatalf("got %v, want %v", err, ErrScheduleLocked)
+	}
+	if _, err := teams.Get(bye); err != nil {
+		t.Errorf("team %d: %v", bye, err)
+	}
+	for _, seasonID := range []int{1, 2} {
+		total, lastWeek, team := seasonFixtures(t, teams, seasonID, bye)
+		if total != 20 || lastWeek != 10 || team != 8 {
+			t.Errorf("season %d: %d fixtures over %d weeks, %d for team %d; want 20, 10 and 8",
+				seasonID, total, lastWeek, team, bye)
+		}
+	}
+}
+
+func TestFairPlayIsKeptPerSeason(t *testing.T) {
+	db := newTestDB(t)
+	teams := NewTeamService(db)
+	seasons := NewSeasonService(db)
+	simulator := NewSimulatorService(db)
+
+	if _, err := teams.SetFairPlay(1, 1, 5); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := seasons.Create("", nil, ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := teams.SetFairPlay(2, 1, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	fairPlay := func(seasonID int) int {
+		t.Helper()
+		standings, err := simulator.GetStandings(seasonID, 0, ViewOverall)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, row := range standings {
+			if row.Team.ID == 1 {
+				return row.Team.FairPlay
+			}
+		}
+		t.Fatal("team 1 is not in the table")
+		return 0
+	}
+
+	if got := fairPlay(1); got != 5 {
+		t.Errorf("season 1: fair_play %d, want 5", got)
+	}
+	if got := fairPlay(2); got != 2 {
+		t.Errorf("season 2: fair_play %d, want 2", got)
+	}
+	if team, err := teams.Get(1); err != nil || team.FairPlay != 2 {
+		t.Errorf("team 1 in the current season: %+v, %v; want fair_play 2", team, err)
+	}
+
+	// Sezon sıfırlanınca sadece kendi puanları silinir
+	if err := seasons.Reset(1); err != nil {
+		t.Fatal(err)
+	}
+	if got := fairPlay(1); got != 0 {
+		t.Errorf("season 1 after reset: fair_play %d, want 0", got)
+	}
+	if got := fairPlay(2); got != 2 {
+		t.Errorf("season 2 after resetting season 1: fair_play %d, want 2", got)
+	}
+
+	if _, err := seasons.Archive(1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := teams.SetFairPlay(1, 1, 3); !errors.Is(err, ErrSeasonArchived) {
+		t.Errorf("archived season: got %v, want %v", err, ErrSeasonArchived)
+	}
+}
@@ -0,0 +1,63 @@
+package services
+
+import "insider-case/models"
+
+// matchStrengths skor modeline verilecek takım güçleri. Saldırı StrengthScale'e, savunma lig
+// ortalamasına göre çarpana çevrilir; böylece ortalama savunma rakibin gol ortalamasını değiştirmez.
+// Sezonun ratings parametresi açıksa Elo puanının güç karşılığı ile sabit güç arasındaki fark
+// saldırıya ve savunmaya eklenir. week 0 değilse o haftadan önceki puanlar kullanılır, böylece
+// tekrar oynatılan bir hafta kendisinin ve sonraki haftaların sonuçlarından etkilenmez;
+// week 0 ise oynanmış tüm maçlardan hesaplanan güncel puanlar kullanılır.
+func matchStrengths(q dbtx, seasonID, week int, params models.SimulationParams) (map[int]models.TeamStrength, error) {
+	rows, err := q.Query("SELECT id, strength, attack, defence FROM teams")
+	if err != nil {
+		return nil, err
+	}
+	type teamRow struct{ id, strength, attack, defence int }
+	var teams []teamRow
+	for rows.Next() {
+		var t teamRow
+		if err := rows.Scan(&t.id, &t.strength, &t.attack, &t.defence); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		teams = append(teams, t)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if params.Ratings {
+		ratings, err := ratingsBefore(q, seasonID, week)
+		if err != nil {
+			return nil, err
+		}
+		for i, t := range teams {
+			if rating, ok := ratings[t.id]; ok {
+				shift := ratingStrength(rating) - t.strength
+				teams[i].attack = clampStrength(t.attack + shift)
+				teams[i].defence = clampStrength(t.defence + shift)
+			}
+		}
+	}
+
+	averageDefence := 0.0
+	for _, t := range teams {
+		averageDefence += float64(t.defence) / float64(len(teams))
+	}
+
+	strengths := make(map[int]models.TeamStrength, len(teams))
+	for _, t := range teams {
+		strengths[t.id] = models.TeamStrength{
+			Attack:  float64(t.attack) / params.StrengthScale,
+			Defence: averageDefence / float64(t.defence),
+		}
+	}
+	return strengths, nil
+}
+
+// clampStrength gücü MinStrength..MaxStrength aralığında tutar
+func clampStrength(strength int) int {
+	return max(MinStrength, min(strength, MaxStrength))
+}
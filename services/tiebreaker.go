@@ -0,0 +1,264 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+)
+
+// Puanları eşit takımları ayırmak için sezon bazında seçilebilen kurallar
+const (
+	TiebreakGoalDifference     = "goal_difference"
+	TiebreakGoalsFor           = "goals_for"
+	TiebreakHeadToHeadPoints   = "head_to_head_points"
+	TiebreakHeadToHeadGoalDiff = "head_to_head_goal_difference"
+	TiebreakAwayGoals          = "away_goals"
+	TiebreakWins               = "wins"
+	TiebreakFairPlay           = "fair_play"
+	TiebreakLots               = "lots"
+)
+
+// tiebreakerSeparator zincirin seasons.tiebreakers kolonunda saklanma ayracı
+const tiebreakerSeparator = ","
+
+var ErrInvalidTiebreaker = errors.New("invalid tiebreaker")
+
+// DefaultTiebreakers yeni sezonlarda kullanılan zincir: averaj, atılan gol, kura
+var DefaultTiebreakers = []string{TiebreakGoalDifference, TiebreakGoalsFor, TiebreakLots}
+
+// playedMatch puan tablosu hesaplanırken okunan oynanmış maç
+type playedMatch struct {
+	home, away, homeGoals, awayGoals int
+}
+
+// tiebreakContext kuralların tablo satırları dışında ihtiyaç duyduğu sezon verisi
+type tiebreakContext struct {
+	matches []playedMatch
+	lots    map[int]int // kura sırası, küçük olan önde
+}
+
+// tiebreaker puanı eşit takımlar için takım ID'sine göre bir anahtar üretir, büyük anahtar öndedir.
+// group hâlâ eşit olan takımlardır; ikili averaj kuralları sadece bu takımlar arasındaki maçlara bakar.
+type tiebreaker func(ctx *tiebreakContext, group []TeamStats) map[int]int
+
+// tiebreakers seçilebilen kuralların kaydı; yeni bir kural buraya eklenerek kullanılabilir
+var tiebreakers = map[string]tiebreaker{
+	TiebreakGoalDifference: statKey(func(t TeamStats) int { return t.GoalDiff }),
+	TiebreakGoalsFor:       statKey(func(t TeamStats) int { return t.GF }),
+	TiebreakWins:           statKey(func(t TeamStats) int { return t.Won }),
+	// fair_play disiplin puanıdır, az olan öndedir
+	TiebreakFairPlay:           statKey(func(t TeamStats) int { return -t.Team.FairPlay }),
+	TiebreakHeadToHeadPoints:   headToHeadPoints,
+	TiebreakHeadToHeadGoalDiff: headToHeadGoalDiff,
+	TiebreakAwayGoals:          awayGoals,
+	TiebreakLots:               drawLots,
+}
+
+func statKey(stat func(TeamStats) int) tiebreaker {
+	return func(_ *tiebreakContext, group []TeamStats) map[int]int {
+		keys := make(map[int]int, len(group))
+		for _, t := range group {
+			keys[t.Team.ID] = stat(t)
+		}
+		return keys
+	}
+}
+
+// headToHead sadece gruptaki takımların birbirleriyle oynadığı maçları f'e verir
+func headToHead(ctx *tiebreakContext, group []TeamStats, f func(keys map[int]int, m playedMatch)) map[int]int {
+	keys := make(map[int]int, len(group))
+	for _, t := range group {
+		keys[t.Team.ID] = 0
+	}
+	for _, m := range ctx.matches {
+		_, home := keys[m.home]
+		_, away := keys[m.away]
+		if home && away {
+			f(keys, m)
+		}
+	}
+	return keys
+}
+
+func headToHeadPoints(ctx *tiebreakContext, group []TeamStats) map[int]int {
+	return headToHead(ctx, group, func(keys map[int]int, m playedMatch) {
+		switch {
+		case m.homeGoals > m.awayGoals:
+			keys[m.home] += 3
+		case m.homeGoals < m.awayGoals:
+			keys[m.away] += 3
+		default:
+			keys[m.home]++
+			keys[m.away]++
+		}
+	})
+}
+
+func headToHeadGoalDiff(ctx *tiebreakContext, group []TeamStats) map[int]int {
+	return headToHead(ctx, group, func(keys map[int]int, m playedMatch) {
+		keys[m.home] += m.homeGoals - m.awayGoals
+		keys[m.away] += m.awayGoals - m.homeGoals
+	})
+}
+
+// awayGoals takımların sezon boyunca deplasmanda attığı goller
+func awayGoals(ctx *tiebreakContext, group []TeamStats) map[int]int {
+	keys := make(map[int]int, len(group))
+	for _, t := range group {
+		keys[t.Team.ID] = 0
+	}
+	for _, m := range ctx.matches {
+		if _, ok := keys[m.away]; ok {
+			keys[m.away] += m.awayGoals
+		}
+	}
+	return keys
+}
+
+func drawLots(ctx *tiebreakContext, group []TeamStats) map[int]int {
+	keys := make(map[int]int, len(group))
+	for _, t := range group {
+		keys[t.Team.ID] = -ctx.lots[t.Team.ID]
+	}
+	return keys
+}
+
+// drawLotOrder takımlar için sezonun kura tohumundan sabit bir sıra çeker.
+// Aynı tohum ve aynı takımlar her istekte aynı sırayı verir.
+func drawLotOrder(teamIDs []int, seed int64) map[int]int {
+	ids := append([]int(nil), teamIDs...)
+	sort.Ints(ids)
+
+	perm := rand.New(rand.NewSource(seed)).Perm(len(ids))
+	lots := make(map[int]int, len(ids))
+	for i, id := range ids {
+		lots[id] = perm[i]
+	}
+	return lots
+}
+
+// ValidateTiebreakers kural isimlerini doğrular ve temizlenmiş listeyi döner
+func ValidateTiebreakers(rules []string) ([]string, error) {
+	seen := make(map[string]bool, len(rules))
+	cleaned := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		rule = strings.TrimSpace(rule)
+		if _, ok := tiebreakers[rule]; !ok {
+			return nil, fmt.Errorf("%w: unknown rule %q", ErrInvalidTiebreaker, rule)
+		}
+		if seen[rule] {
+			return nil, fmt.Errorf("%w: rule %q is listed twice", ErrInvalidTiebreaker, rule)
+		}
+		seen[rule] = true
+		cleaned = append(cleaned, rule)
+	}
+	return cleaned, nil
+}
+
+// parseTiebreakers seasons tablosunda virgülle saklanan zinciri listeye çevirir,
+// kayıtlı olmayan kurallar atlanır
+func parseTiebreakers(stored string) []string {
+	rules := []string{}
+	for _, rule := range strings.Split(stored, tiebreakerSeparator) {
+		if _, ok := tiebreakers[strings.TrimSpace(rule)]; ok {
+			rule = strings.TrimSpace(rule)
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// seasonTiebreakers sezonun kural zincirini ve kura tohumunu okur
+func seasonTiebreakers(q dbtx, seasonID int) ([]string, int64, error) {
+	var stored string
+	var seed int64
+	err := q.QueryRow("SELECT tiebreakers, lots_seed FROM seasons WHERE id = ?", seasonID).Scan(&stored, &seed)
+	if err != nil {
+		return nil, 0, err
+	}
+	return parseTiebreakers(stored), seed, nil
+}
+
+// seasonFairPlay takımların sezondaki fair-play puanlarını okur; puanı girilmemiş takımlar 0'dır
+func seasonFairPlay(q dbtx, seasonID int) (map[int]int, error) {
+	rows, err := q.Query("SELECT team_id, points FROM team_fair_play WHERE season_id = ?", seasonID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	points := make(map[int]int)
+	for rows.Next() {
+		var teamID, p int
+		if err := rows.Scan(&teamID, &p); err != nil {
+			return nil, err
+		}
+		points[teamID] = p
+	}
+	return points, rows.Err()
+}
+
+// rankStandings tabloyu önce puana, puanı eşit takımları da sırayla rules'a göre sıralar.
+// Zincirde kura yoksa en sona eklenir, böylece sıralama her istekte aynıdır.
+// Puanı komşusuyla eşit olan her satıra, onu o komşudan ayıran kural yazılır.
+func rankStandings(standings []TeamStats, rules []string, ctx *tiebreakContext) {
+	hasLots := false
+	for _, rule := range rules {
+		hasLots = hasLots || rule == TiebreakLots
+	}
+	if !hasLots {
+		rules = append(rules[:len(rules):len(rules)], TiebreakLots)
+	}
+
+	sort.SliceStable(standings, func(i, j int) bool {
+		return standings[i].Points > standings[j].Points
+	})
+
+	// decided[i], i. ve i+1. satırları ayıran kuraldır
+	decided := make([]string, len(standings))
+	for start := 0; start < len(standings); {
+		end := start + 1
+		for end < len(standings) && standings[end].Points == standings[start].Points {
+			end++
+		}
+		breakTie(standings, start, end, rules, ctx, decided)
+		start = end
+	}
+
+	for i := range standings {
+		switch {
+		case i > 0 && standings[i-1].Points == standings[i].Points:
+			standings[i].Tiebreaker = decided[i-1]
+		case i+1 < len(standings) && standings[i+1].Points == standings[i].Points:
+			standings[i].Tiebreaker = decided[i]
+		}
+	}
+}
+
+// breakTie [start, end) aralığındaki eşit takımları ilk kurala göre sıralar, hâlâ
+// eşit kalan alt grupları kalan kurallarla özyinelemeli olarak ayırır
+func breakTie(standings []TeamStats, start, end int, rules []string, ctx *tiebreakContext, decided []string) {
+	if end-start < 2 || len(rules) == 0 {
+		return
+	}
+
+	group := standings[start:end]
+	keys := tiebreakers[rules[0]](ctx, group)
+	sort.SliceStable(group, func(i, j int) bool {
+		return keys[group[i].Team.ID] > keys[group[j].Team.ID]
+	})
+
+	for i := start; i < end; {
+		j := i + 1
+		for j < end && keys[standings[j].Team.ID] == keys[standings[i].Team.ID] {
+			j++
+		}
+		if j < end {
+			decided[j-1] = rules[0]
+		}
+		breakTie(standings, i, j, rules[1:], ctx, decided)
+		i = j
+	}
+}
@@ -0,0 +1,129 @@
+package services
+
+import (
+	"insider-case/models"
+	"reflect"
+	"testing"
+)
+
+// row testlerde kullanılan kısa tablo satırı
+type row struct {
+	id, points, goalDiff, goalsFor, won, fairPlay int
+}
+
+func (r row) stats() TeamStats {
+	return TeamStats{
+		Team:       models.Team{ID: r.id, FairPlay: r.fairPlay},
+		VenueStats: VenueStats{Points: r.points, GoalDiff: r.goalDiff, GF: r.goalsFor, Won: r.won},
+	}
+}
+
+func TestRankStandings(t *testing.T) {
+	tests := []struct {
+		name        string
+		rows        []row
+		rules       []string
+		matches     []playedMatch
+		lots        map[int]int
+		wantOrder   []int
+		wantDecided map[int]string
+	}{
+		{
+			name:        "points only",
+			rows:        []row{{id: 1, points: 3}, {id: 2, points: 9}, {id: 3, points: 6}},
+			rules:       DefaultTiebreakers,
+			wantOrder:   []int{2, 3, 1},
+			wantDecided: map[int]string{},
+		},
+		{
+			name:        "goal difference then goals for",
+			rows:        []row{{id: 1, points: 6, goalDiff: 2, goalsFor: 5}, {id: 2, points: 6, goalDiff: 2, goalsFor: 7}, {id: 3, points: 6, goalDiff: 4, goalsFor: 4}},
+			rules:       DefaultTiebreakers,
+			wantOrder:   []int{3, 2, 1},
+			wantDecided: map[int]string{3: TiebreakGoalDifference, 2: TiebreakGoalDifference, 1: TiebreakGoalsFor},
+		},
+		{
+			name:  "head to head before goal difference",
+			rows:  []row{{id: 1, points: 4, goalDiff: 5}, {id: 2, points: 4, goalDiff: 1}},
+			rules: []string{TiebreakHeadToHeadPoints, TiebreakGoalDifference},
+			matches: []playedMatch{
+				{home: 2, away: 1, homeGoals: 1, awayGoals: 0},
+				{home: 1, away: 3, homeGoals: 5, awayGoals: 0},
+			},
+			wantOrder:   []int{2, 1},
+			wantDecided: map[int]string{2: TiebreakHeadToHeadPoints, 1: TiebreakHeadToHeadPoints},
+		},
+		{
+			name:  "head to head only counts matches inside the tied group",
+			rows:  []row{{id: 1, points: 3}, {id: 2, points: 3}, {id: 3, points: 0}},
+			rules: []string{TiebreakHeadToHeadGoalDiff, TiebreakLots},
+			matches: []playedMatch{
+				{home: 1, away: 2, homeGoals: 1, awayGoals: 1},
+				{home: 2, away: 3, homeGoals: 6, awayGoals: 0},
+			},
+			lots:        map[int]int{1: 0, 2: 1, 3: 2},
+			wantOrder:   []int{1, 2, 3},
+			wantDecided: map[int]string{1: TiebreakLots, 2: TiebreakLots},
+		},
+		{
+			name:        "fair play, fewer points first",
+			rows:        []row{{id: 1, points: 5, fairPlay: 4}, {id: 2, points: 5, fairPlay: 1}},
+			rules:       []string{TiebreakFairPlay},
+			wantOrder:   []int{2, 1},
+			wantDecided: map[int]string{2: TiebreakFairPlay, 1: TiebreakFairPlay},
+		},
+		{
+			name:        "lots are appended when missing from the chain",
+			rows:        []row{{id: 1, points: 1}, {id: 2, points: 1}, {id: 3, points: 1}},
+			rules:       []string{TiebreakWins},
+			lots:        map[int]int{1: 2, 2: 0, 3: 1},
+			wantOrder:   []int{2, 3, 1},
+			wantDecided: map[int]string{1: TiebreakLots, 2: TiebreakLots, 3: TiebreakLots},
+		},
+		{
+			name:        "second rule only splits the teams the first one left tied",
+			rows:        []row{{id: 1, points: 7, goalDiff: 1, won: 1}, {id: 2, points: 7, goalDiff: 3, won: 0}, {id: 3, points: 7, goalDiff: 1, won: 2}},
+			rules:       []string{TiebreakGoalDifference, TiebreakWins},
+			wantOrder:   []int{2, 3, 1},
+			wantDecided: map[int]string{2: TiebreakGoalDifference, 3: TiebreakGoalDifference, 1: TiebreakWins},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			standings := make([]TeamStats, len(tt.rows))
+			for i, r := range tt.rows {
+				standings[i] = r.stats()
+			}
+			lots := tt.lots
+			if lots == nil {
+				lots = map[int]int{}
+			}
+
+			rankStandings(standings, tt.rules, &tiebreakContext{matches: tt.matches, lots: lots})
+
+			order := make([]int, len(standings))
+			decided := make(map[int]string)
+			for i, s := range standings {
+				order[i] = s.Team.ID
+				if s.Tiebreaker != "" {
+					decided[s.Team.ID] = s.Tiebreaker
+				}
+			}
+			if !reflect.DeepEqual(order, tt.wantOrder) {
+				t.Errorf("order = %v, want %v", order, tt.wantOrder)
+			}
+			if !reflect.DeepEqual(decided, tt.wantDecided) {
+				t.Errorf("tiebreakers = %v, want %v", decided, tt.wantDecided)
+			}
+		})
+	}
+}
+
+func TestDrawLotOrderIsStable(t *testing.T) {
+	first := drawLotOrder([]int{4, 1, 3, 2}, 99)
+	second := drawLotOrder([]int{1, 2, 3, 4}, 99)
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("same seed and teams gave %v and %v", first, second)
+	}
+}